@@ -20,6 +20,7 @@ package v2
 type RoleSpec struct {
 	ID          string `json:"id" yaml:"id"`
 	DomainID    string `json:"domain_id" yaml:"domain_id"`
+	Domain      string `json:"domain,omitempty" yaml:"domain,omitempty"`           // (optional) domain name of a domain-specific role
 	Name        string `json:"name" yaml:"name"`                                   // the role name
 	Description string `json:"description,omitempty" yaml:"description,omitempty"` // the role description
 }
@@ -67,6 +68,16 @@ type EndpointSpec struct {
 	Enabled bool   `json:"enabled" yaml:"enabled"` // boolean flag to indicate if the endpoint is enabled
 }
 
+// A keystone-to-keystone federation service provider (see https://docs.openstack.org/api-ref/identity/v3-ext/#service-providers)
+type ServiceProviderSpec struct {
+	ID               string `json:"id" yaml:"id"`                                                     // the service provider id
+	Description      string `json:"description,omitempty" yaml:"description,omitempty"`               // description of the service provider
+	AuthURL          string `json:"auth_url" yaml:"auth_url"`                                         // the remote keystones federated authentication URL
+	SPURL            string `json:"sp_url" yaml:"sp_url"`                                             // the remote keystones SAML2 ECP endpoint
+	RelayStatePrefix string `json:"relay_state_prefix,omitempty" yaml:"relay_state_prefix,omitempty"` // (optional) relay state prefix of the ECP wrapped SAML messages
+	Enabled          *bool  `json:"enabled,omitempty" yaml:"enabled,omitempty"`                       // boolean flag to indicate if the service provider is enabled
+}
+
 // A keystone domain (see https://developer.openstack.org/api-ref/identity/v3/index.html#domains)
 type DomainSpec struct {
 	ID              string               `json:"id" yaml:"id"`
@@ -150,25 +161,34 @@ type LdapConfigSpec struct {
 
 // A keystone project (see https://developer.openstack.org/api-ref/identity/v3/index.html#projects)
 type ProjectSpec struct {
-	Name            string                `json:"name" yaml:"name"`                                             // project name
-	Description     string                `json:"description,omitempty" yaml:"description,omitempty"`           // project description
-	Enabled         *bool                 `json:"enabled,omitempty" yaml:"enabled,omitempty"`                   // boolean flag to indicate if the project is enabled
-	Parent          string                `json:"parent,omitempty" yaml:"parent,omitempty"`                     // (optional) parent project name
-	IsDomain        *bool                 `json:"is_domain,omitempty" yaml:"is_domain,omitempty"`               // is the project actually a domain?
-	Endpoints       []ProjectEndpointSpec `json:"endpoints,omitempty" yaml:"endpoints,omitempty"`               // list of project endpoint filters
-	RoleAssignments []RoleAssignmentSpec  `json:"role_assignments,omitempty" yaml:"role_assignments,omitempty"` // list of project-role-assignments
-	Flavors         []string              `json:"flavors,omitempty" yaml:"flavors,omitempty"`                   // list of nova flavor-id's
-	ShareTypes      []string              `json:"share_types,omitempty" yaml:"share_types,omitempty"`           // list of manila share types
-	AddressScopes   []AddressScopeSpec    `json:"address_scopes,omitempty" yaml:"address_scopes,omitempty"`     // list of neutron address-scopes
-	SubnetPools     []SubnetPoolSpec      `json:"subnet_pools,omitempty" yaml:"subnet_pools,omitempty"`         // list of neutron subnet-pools
-	NetworkQuota    *NetworkQuotaSpec     `json:"network_quota,omitempty" yaml:"network_quota,omitempty"`       // neutron quota
-	Networks        []NetworkSpec         `json:"networks,omitempty" yaml:"networks,omitempty"`                 // neutron networks
-	Routers         []RouterSpec          `json:"routers,omitempty" yaml:"routers,omitempty"`                   // neutron routers
-	Swift           *SwiftAccountSpec     `json:"swift,omitempty" yaml:"swift,omitempty"`                       // swift account
-	DNSQuota        *DNSQuotaSpec         `json:"dns_quota,omitempty" yaml:"dns_quota,omitempty"`               // designate quota
-	DNSZones        []DNSZoneSpec         `json:"dns_zones,omitempty" yaml:"dns_zones,omitempty"`               // designate zones, recordsets
-	DNSTSIGKeys     []DNSTSIGKeySpec      `json:"dns_tsigkeys,omitempty" yaml:"dns_tsigkeys,omitempty"`         // designate tsig keys
-	Ec2Creds        []Ec2CredSpec         `json:"ec2_creds,omitempty" yaml:"ec2_creds,omitempty"`               // ec2 credentions for user
+	Name             string                `json:"name" yaml:"name"`                                               // project name
+	Description      string                `json:"description,omitempty" yaml:"description,omitempty"`             // project description
+	Enabled          *bool                 `json:"enabled,omitempty" yaml:"enabled,omitempty"`                     // boolean flag to indicate if the project is enabled
+	Parent           string                `json:"parent,omitempty" yaml:"parent,omitempty"`                       // (optional) parent project name
+	IsDomain         *bool                 `json:"is_domain,omitempty" yaml:"is_domain,omitempty"`                 // is the project actually a domain?
+	Endpoints        []ProjectEndpointSpec `json:"endpoints,omitempty" yaml:"endpoints,omitempty"`                 // list of project endpoint filters
+	RoleAssignments  []RoleAssignmentSpec  `json:"role_assignments,omitempty" yaml:"role_assignments,omitempty"`   // list of project-role-assignments
+	Flavors          []string              `json:"flavors,omitempty" yaml:"flavors,omitempty"`                     // list of nova flavor-id's
+	ComputeQuota     *ComputeQuotaSpec     `json:"compute_quota,omitempty" yaml:"compute_quota,omitempty"`         // nova quota
+	VolumeQuota      *VolumeQuotaSpec      `json:"volume_quota,omitempty" yaml:"volume_quota,omitempty"`           // cinder quota
+	ShareTypes       []string              `json:"share_types,omitempty" yaml:"share_types,omitempty"`             // list of manila share types
+	ShareQuota       *ShareQuotaSpec       `json:"share_quota,omitempty" yaml:"share_quota,omitempty"`             // manila quota
+	SecurityServices []SecurityServiceSpec `json:"security_services,omitempty" yaml:"security_services,omitempty"` // manila security services (the seeder user needs the member role in the project)
+	ShareNetworks    []ShareNetworkSpec    `json:"share_networks,omitempty" yaml:"share_networks,omitempty"`       // manila share networks (the seeder user needs the member role in the project)
+	AddressScopes    []AddressScopeSpec    `json:"address_scopes,omitempty" yaml:"address_scopes,omitempty"`       // list of neutron address-scopes
+	SubnetPools      []SubnetPoolSpec      `json:"subnet_pools,omitempty" yaml:"subnet_pools,omitempty"`           // list of neutron subnet-pools
+	NetworkQuota     *NetworkQuotaSpec     `json:"network_quota,omitempty" yaml:"network_quota,omitempty"`         // neutron quota
+	Networks         []NetworkSpec         `json:"networks,omitempty" yaml:"networks,omitempty"`                   // neutron networks
+	Routers          []RouterSpec          `json:"routers,omitempty" yaml:"routers,omitempty"`                     // neutron routers
+	SecurityGroups   []SecurityGroupSpec   `json:"security_groups,omitempty" yaml:"security_groups,omitempty"`     // neutron security groups
+	QosPolicies      []QosPolicySpec       `json:"qos_policies,omitempty" yaml:"qos_policies,omitempty"`           // neutron qos policies
+	Ports            []PortSpec            `json:"ports,omitempty" yaml:"ports,omitempty"`                         // neutron ports with fixed addresses
+	FloatingIPs      []FloatingIPSpec      `json:"floating_ips,omitempty" yaml:"floating_ips,omitempty"`           // neutron floating ips with fixed addresses
+	Swift            *SwiftAccountSpec     `json:"swift,omitempty" yaml:"swift,omitempty"`                         // swift account
+	DNSQuota         *DNSQuotaSpec         `json:"dns_quota,omitempty" yaml:"dns_quota,omitempty"`                 // designate quota
+	DNSZones         []DNSZoneSpec         `json:"dns_zones,omitempty" yaml:"dns_zones,omitempty"`                 // designate zones, recordsets
+	DNSTSIGKeys      []DNSTSIGKeySpec      `json:"dns_tsigkeys,omitempty" yaml:"dns_tsigkeys,omitempty"`           // designate tsig keys
+	Ec2Creds         []Ec2CredSpec         `json:"ec2_creds,omitempty" yaml:"ec2_creds,omitempty"`                 // ec2 credentions for user
 }
 
 // A project endpoint filter (see https://developer.openstack.org/api-ref/identity/v3-ext/#os-ep-filter-api)
@@ -184,8 +204,9 @@ type ProjectEndpointSpec struct {
 // A role assignment always links 3 entities: user or group to project or domain with a specified role.
 //
 // To support cross domain entity referals, the user-, group- or project-names support a name@domain notation.
+// Domain-specific roles are referenced as role@domain.
 type RoleAssignmentSpec struct {
-	Role      string `json:"role" yaml:"role"`                                 // the role name
+	Role      string `json:"role" yaml:"role"`                                 // the role name (or role@domain for a domain-specific role)
 	Domain    string `json:"domain,omitempty" yaml:"domain,omitempty"`         // domain-role-assigment: the domain name
 	Project   string `json:"project,omitempty" yaml:"project,omitempty"`       // project-role-assignment: project_name@domain_name
 	ProjectID string `json:"project_id,omitempty" yaml:"project_id,omitempty"` // project-role assignment: project id
@@ -204,6 +225,20 @@ type UserSpec struct {
 	Enabled          *bool                `json:"enabled,omitempty" yaml:"enabled,omitempty"`                   // boolean flag to indicate if the user is enabled
 	RoleAssignments  []RoleAssignmentSpec `json:"role_assignments,omitempty" yaml:"role_assignments,omitempty"` // list of the users role-assignments
 	DefaultProjectID string               `json:"default_project,omitempty" yaml:"default_project,omitempty"`   // default project scope for the user
+	Credentials      []CredentialSpec     `json:"credentials,omitempty" yaml:"credentials,omitempty"`           // list of the users credentials (e.g. totp secrets)
+}
+
+// A keystone credential of a user (see https://developer.openstack.org/api-ref/identity/v3/index.html#credentials)
+type CredentialSpec struct {
+	Type    string       `json:"type" yaml:"type"`                           // credential type, e.g. totp, cert or ec2
+	Blob    SecretKeyRef `json:"blob" yaml:"blob"`                           // reference to the secret key holding the credential blob
+	Project string       `json:"project,omitempty" yaml:"project,omitempty"` // (optional) project scope of the credential: project_name@domain_name
+}
+
+// SecretKeyRef references a key of a kubernetes secret in the namespace of the seed
+type SecretKeyRef struct {
+	Name string `json:"name" yaml:"name"` // secret name
+	Key  string `json:"key" yaml:"key"`   // key within the secret
 }
 
 // A keystone group (see https://developer.openstack.org/api-ref/identity/v3/#groups)
@@ -229,12 +264,38 @@ type FlavorSpec struct {
 	ExtraSpecs map[string]string `json:"extra_specs,omitempty" yaml:"extra_specs,omitempty"` // list of extra specs
 }
 
+// A nova host aggregate (see https://developer.openstack.org/api-ref/compute/#host-aggregates-os-aggregates)
+type AggregateSpec struct {
+	Name             string            `json:"name" yaml:"name"`                                               // aggregate name
+	AvailabilityZone string            `json:"availability_zone,omitempty" yaml:"availability_zone,omitempty"` // (optional) availability zone of the aggregate; if empty, the aggregate has none
+	Metadata         map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`                   // aggregate metadata (e.g. used by the scheduler filters)
+	Hosts            []string          `json:"hosts,omitempty" yaml:"hosts,omitempty"`                         // (optional) member hosts; if empty, the membership is not managed
+}
+
 // A Cinder Volume Type
 type VolumeTypeSpec struct {
-	Name        string            `json:"name" yaml:"name"`                                   // volume type name
-	Description string            `json:"description,omitempty" yaml:"description,omitempty"` // description
-	IsPublic    *bool             `json:"is_public,omitempty" yaml:"is_public,omitempty"`     // volume type is public or private; deafult is public
-	ExtraSpecs  map[string]string `json:"extra_specs,omitempty" yaml:"extra_specs,omitempty"` // extra specs that are not typed or validated
+	Name        string                    `json:"name" yaml:"name"`                                   // volume type name
+	Description string                    `json:"description,omitempty" yaml:"description,omitempty"` // description
+	IsPublic    *bool                     `json:"is_public,omitempty" yaml:"is_public,omitempty"`     // volume type is public or private; deafult is public
+	ExtraSpecs  map[string]string         `json:"extra_specs,omitempty" yaml:"extra_specs,omitempty"` // extra specs that are not typed or validated
+	Projects    []string                  `json:"projects,omitempty" yaml:"projects,omitempty"`       // projects with access to a private volume type: project_name@domain_name
+	Encryption  *VolumeTypeEncryptionSpec `json:"encryption,omitempty" yaml:"encryption,omitempty"`   // (optional) volume encryption
+}
+
+// A Cinder Volume Type encryption (see https://docs.openstack.org/api-ref/block-storage/v3/#volume-type-encryption)
+type VolumeTypeEncryptionSpec struct {
+	Provider        string `json:"provider" yaml:"provider"`                                     // encryption provider, e.g. luks
+	Cipher          string `json:"cipher,omitempty" yaml:"cipher,omitempty"`                     // encryption algorithm or mode, e.g. aes-xts-plain64
+	KeySize         int    `json:"key_size,omitempty" yaml:"key_size,omitempty"`                 // size of the encryption key in bits, e.g. 256
+	ControlLocation string `json:"control_location,omitempty" yaml:"control_location,omitempty"` // notional service where encryption is performed: front-end (default) or back-end
+}
+
+// A Cinder QoS spec (see https://docs.openstack.org/api-ref/block-storage/v3/#quality-of-service-qos-specs-qos-specs)
+type QosSpecSpec struct {
+	Name        string            `json:"name" yaml:"name"`                                     // qos spec name
+	Consumer    string            `json:"consumer,omitempty" yaml:"consumer,omitempty"`         // consumer of the qos spec: front-end, back-end (default) or both
+	Specs       map[string]string `json:"specs,omitempty" yaml:"specs,omitempty"`               // qos specs, e.g. total_iops_sec or total_bytes_sec
+	VolumeTypes []string          `json:"volume_types,omitempty" yaml:"volume_types,omitempty"` // names of the volume types associated with the qos spec
 }
 
 // A Manila Share Type (see https://developer.openstack.org/api-ref/shared-file-system/?expanded=create-share-type-detail#share-types )
@@ -251,6 +312,39 @@ type ShareTypeSpecifiedSpecs struct {
 	SnapshotSupport *bool `json:"snapshot_support,omitempty" yaml:"snapshot_support,omitempty"`     // snapshot support, optional
 }
 
+// A manila project quota (see https://docs.openstack.org/api-ref/shared-file-system/#quota-sets)
+type ShareQuotaSpec struct {
+	Shares              int `json:"shares,omitempty" yaml:"shares,omitempty"`                               // The number of shares that are allowed for each project. A value of -1 means no limit.
+	Gigabytes           int `json:"gigabytes,omitempty" yaml:"gigabytes,omitempty"`                         // The total size (GB) of shares that are allowed for each project. A value of -1 means no limit.
+	Snapshots           int `json:"snapshots,omitempty" yaml:"snapshots,omitempty"`                         // The number of share snapshots that are allowed for each project. A value of -1 means no limit.
+	SnapshotGigabytes   int `json:"snapshot_gigabytes,omitempty" yaml:"snapshot_gigabytes,omitempty"`       // The total size (GB) of share snapshots that are allowed for each project. A value of -1 means no limit.
+	ShareNetworks       int `json:"share_networks,omitempty" yaml:"share_networks,omitempty"`               // The number of share networks that are allowed for each project. A value of -1 means no limit.
+	ShareGroups         int `json:"share_groups,omitempty" yaml:"share_groups,omitempty"`                   // The number of share groups that are allowed for each project. A value of -1 means no limit.
+	ShareGroupSnapshots int `json:"share_group_snapshots,omitempty" yaml:"share_group_snapshots,omitempty"` // The number of share group snapshots that are allowed for each project. A value of -1 means no limit.
+}
+
+// A manila share network (see https://docs.openstack.org/api-ref/shared-file-system/#share-networks)
+type ShareNetworkSpec struct {
+	Name             string   `json:"name" yaml:"name"`                                               // share network name
+	Description      string   `json:"description,omitempty" yaml:"description,omitempty"`             // description of the share network
+	Network          string   `json:"network" yaml:"network"`                                         // neutron network-name (network-name or network-name@project@domain)
+	Subnet           string   `json:"subnet" yaml:"subnet"`                                           // neutron subnet-name (subnet-name or subnet-name@project@domain)
+	SecurityServices []string `json:"security_services,omitempty" yaml:"security_services,omitempty"` // names of the security services of the project to associate
+}
+
+// A manila security service (see https://docs.openstack.org/api-ref/shared-file-system/#security-services)
+type SecurityServiceSpec struct {
+	Name        string        `json:"name" yaml:"name"`                                   // security service name
+	Type        string        `json:"type" yaml:"type"`                                   // security service type: active_directory, kerberos or ldap
+	Description string        `json:"description,omitempty" yaml:"description,omitempty"` // description of the security service
+	DNSIP       string        `json:"dns_ip,omitempty" yaml:"dns_ip,omitempty"`           // The DNS IP address that is used inside the tenant network.
+	OU          string        `json:"ou,omitempty" yaml:"ou,omitempty"`                   // The organizational unit (OU).
+	Server      string        `json:"server,omitempty" yaml:"server,omitempty"`           // The security service host name or IP address.
+	Domain      string        `json:"domain,omitempty" yaml:"domain,omitempty"`           // The security service domain.
+	User        string        `json:"user,omitempty" yaml:"user,omitempty"`               // The security service user or group name that is used by the project.
+	Password    *SecretKeyRef `json:"password,omitempty" yaml:"password,omitempty"`       // reference to the secret key holding the password of the user
+}
+
 // A neutron address scope (see https://developer.openstack.org/api-ref/networking/v2/index.html  UNDOCUMENTED)
 type AddressScopeSpec struct {
 	Name        string           `json:"name" yaml:"name"`                                     // address scope name
@@ -268,12 +362,39 @@ type SubnetPoolSpec struct {
 	Shared           *bool    `json:"shared,omitempty" yaml:"shared,omitempty"`                       // Admin-only. Indicates whether this network is shared across all projects.
 	DefaultPrefixLen int      `json:"default_prefixlen,omitempty" yaml:"default_prefixlen,omitempty"` // The size of the prefix to allocate when the cidr or prefixlen attributes are omitted when you create the subnet. Default is min_prefixlen.
 	MaxPrefixLen     int      `json:"max_prefixlen,omitempty" yaml:"max_prefixlen,omitempty"`         // The maximum prefix size that can be allocated from the subnet pool. For IPv4 subnet pools, default is 32. For IPv6 subnet pools, default is 128.
-	AddressScopeId   string   `json:"address_scope_id,omitempty" yaml:"address_scope_id,omitempty"`   // An address scope to assign to the subnet pool.
+	AddressScopeId   string   `json:"address_scope_id,omitempty" yaml:"address_scope_id,omitempty"`   // An address scope to assign to the subnet pool. Subnet pools of an address scope are assigned to it automatically.
 	IsDefault        *bool    `json:"is_default,omitempty" yaml:"is_default,omitempty"`
 	Description      string   `json:"description,omitempty" yaml:"description,omitempty"` // description of the subnet-pool
 }
 
+// A nova project quota (see https://developer.openstack.org/api-ref/compute/#quota-sets-os-quota-sets)
+type ComputeQuotaSpec struct {
+	Instances                int `json:"instances,omitempty" yaml:"instances,omitempty"`                                     // The number of allowed servers for each project. A value of -1 means no limit.
+	Cores                    int `json:"cores,omitempty" yaml:"cores,omitempty"`                                             // The number of allowed server cores for each project. A value of -1 means no limit.
+	RAM                      int `json:"ram,omitempty" yaml:"ram,omitempty"`                                                 // The amount of allowed server RAM, in MiB, for each project. A value of -1 means no limit.
+	KeyPairs                 int `json:"key_pairs,omitempty" yaml:"key_pairs,omitempty"`                                     // The number of allowed key pairs for each user.
+	MetadataItems            int `json:"metadata_items,omitempty" yaml:"metadata_items,omitempty"`                           // The number of allowed metadata items for each server.
+	ServerGroups             int `json:"server_groups,omitempty" yaml:"server_groups,omitempty"`                             // The number of allowed server groups for each project.
+	ServerGroupMembers       int `json:"server_group_members,omitempty" yaml:"server_group_members,omitempty"`               // The number of allowed members for each server group.
+	InjectedFiles            int `json:"injected_files,omitempty" yaml:"injected_files,omitempty"`                           // The number of allowed injected files for each project.
+	InjectedFileContentBytes int `json:"injected_file_content_bytes,omitempty" yaml:"injected_file_content_bytes,omitempty"` // The number of allowed bytes of content for each injected file.
+	InjectedFilePathBytes    int `json:"injected_file_path_bytes,omitempty" yaml:"injected_file_path_bytes,omitempty"`       // The number of allowed bytes for each injected file path.
+}
+
+// A cinder project quota (see https://docs.openstack.org/api-ref/block-storage/v3/#quota-sets-extension-os-quota-sets)
+type VolumeQuotaSpec struct {
+	Volumes            int            `json:"volumes,omitempty" yaml:"volumes,omitempty"`                           // The number of volumes that are allowed for each project. A value of -1 means no limit.
+	Gigabytes          int            `json:"gigabytes,omitempty" yaml:"gigabytes,omitempty"`                       // The size (GB) of volumes and snapshots that are allowed for each project. A value of -1 means no limit.
+	Snapshots          int            `json:"snapshots,omitempty" yaml:"snapshots,omitempty"`                       // The number of snapshots that are allowed for each project. A value of -1 means no limit.
+	Backups            int            `json:"backups,omitempty" yaml:"backups,omitempty"`                           // The number of backups that are allowed for each project. A value of -1 means no limit.
+	BackupGigabytes    int            `json:"backup_gigabytes,omitempty" yaml:"backup_gigabytes,omitempty"`         // The size (GB) of backups that are allowed for each project. A value of -1 means no limit.
+	PerVolumeGigabytes int            `json:"per_volume_gigabytes,omitempty" yaml:"per_volume_gigabytes,omitempty"` // The size (GB) of each volume. A value of -1 means no limit.
+	Groups             int            `json:"groups,omitempty" yaml:"groups,omitempty"`                             // The number of groups that are allowed for each project. A value of -1 means no limit.
+	VolumeTypes        map[string]int `json:"volume_types,omitempty" yaml:"volume_types,omitempty"`                 // per volume type quotas: volumes_<type>, gigabytes_<type> or snapshots_<type>
+}
+
 // A neutron project quota (see https://developer.openstack.org/api-ref/networking/v2/index.html#quotas-extension-quotas)
+// The load balancer keys are seeded through octavia if the catalog has a load-balancer service, or through neutron-lbaas otherwise.
 type NetworkQuotaSpec struct {
 	FloatingIP        int `json:"floatingip,omitempty" yaml:"floatingip,omitempty"`                   // The number of floating IP addresses allowed for each project. A value of -1 means no limit.
 	Network           int `json:"network,omitempty" yaml:"network,omitempty"`                         // The number of networks allowed for each project. A value of -1 means no limit.
@@ -284,18 +405,18 @@ type NetworkQuotaSpec struct {
 	SecurityGroupRule int `json:"security_group_rule,omitempty" yaml:"security_group_rule,omitempty"` // The number of security group rules allowed for each project. A value of -1 means no limit.
 	Subnet            int `json:"subnet,omitempty" yaml:"subnet,omitempty"`                           // The number of subnets allowed for each project. A value of -1 means no limit.
 	SubnetPool        int `json:"subnetpool,omitempty" yaml:"subnetpool,omitempty"`                   // The number of subnet pools allowed for each project. A value of -1 means no limit.
-	HealthMonitor     int `json:"healthmonitor,omitempty" yaml:"healthmonitor,omitempty"`             //
-	L7Policy          int `json:"l7policy,omitempty" yaml:"l7policy,omitempty"`                       //
-	Listener          int `json:"listener,omitempty" yaml:"listener,omitempty"`                       //
-	LoadBalancer      int `json:"loadbalancer,omitempty" yaml:"loadbalancer,omitempty"`               //
+	HealthMonitor     int `json:"healthmonitor,omitempty" yaml:"healthmonitor,omitempty"`             // The number of health monitors allowed for each project. A value of -1 means no limit.
+	L7Policy          int `json:"l7policy,omitempty" yaml:"l7policy,omitempty"`                       // The number of L7 policies allowed for each project. A value of -1 means no limit.
+	Listener          int `json:"listener,omitempty" yaml:"listener,omitempty"`                       // The number of listeners allowed for each project. A value of -1 means no limit.
+	LoadBalancer      int `json:"loadbalancer,omitempty" yaml:"loadbalancer,omitempty"`               // The number of load balancers allowed for each project. A value of -1 means no limit.
 }
 
 // A neutron RBAC policy (see https://developer.openstack.org/api-ref/network/v2/index.html#rbac-policies)
 type RBACPolicySpec struct {
-	ObjectType       string `json:"object_type" yaml:"object_type"`               // The type of the object that the RBAC policy affects. Types include qos-policy or network.
-	ObjectName       string `json:"object_name" yaml:"object_name"`               // The name of the object (like networkname@project@domain) or
-	Action           string `json:"action" yaml:"action"`                         // Action for the RBAC policy which is access_as_external or access_as_shared.
-	TargetTenantName string `json:"target_tenant_name" yaml:"target_tenant_name"` // The name of the target tenant (project@domain) or
+	ObjectType       string `json:"object_type" yaml:"object_type"`               // The type of the object that the RBAC policy affects: network, qos_policy, security_group, address_scope, address_group or subnetpool.
+	ObjectName       string `json:"object_name" yaml:"object_name"`               // The name of the object (name or name@project@domain). Plain names are looked up in all projects.
+	Action           string `json:"action" yaml:"action"`                         // Action for the RBAC policy which is access_as_external (networks only) or access_as_shared.
+	TargetTenantName string `json:"target_tenant_name" yaml:"target_tenant_name"` // The name of the target tenant (project@domain) or * for all projects.
 }
 
 // A neutron network (see https://developer.openstack.org/api-ref/networking/v2/index.html#networks)
@@ -306,7 +427,7 @@ type NetworkSpec struct {
 	ProviderNetworkType     string       `json:"provider_network_type,omitempty" yaml:"provider_network_type,omitempty"`         // The type of physical network that this network should be mapped to. For example, flat, vlan, vxlan, or gre. Valid values depend on a networking back-end.
 	ProviderPhysicalNetwork string       `json:"provider_physical_network,omitempty" yaml:"provider_physical_network,omitempty"` // The physical network where this network should be implemented. The Networking API v2.0 does not provide a way to list available physical networks. For example, the Open vSwitch plug-in configuration file defines a symbolic name that maps to specific bridges on each compute host.
 	ProviderSegmentationId  string       `json:"provider_segmentation_id,omitempty" yaml:"provider_segmentation_id,omitempty"`   // The ID of the isolated segment on the physical network. The network_type attribute defines the segmentation model. For example, if the network_type value is vlan, this ID is a vlan identifier. If the network_type value is gre, this ID is a gre key.
-	QosPolicyId             string       `json:"qos_policy_id,omitempty" yaml:"qos_policy_id,omitempty"`                         // The ID of the QoS policy.
+	QosPolicyId             string       `json:"qos_policy_id,omitempty" yaml:"qos_policy_id,omitempty"`                         // The ID or name (name or name@project@domain) of the QoS policy.
 	RouterExternal          *bool        `json:"router_external,omitempty" yaml:"router_external,omitempty"`                     // Indicates whether this network can provide floating IPs via a router.
 	Shared                  *bool        `json:"shared,omitempty" yaml:"shared,omitempty"`                                       // Indicates whether this network is shared across all projects. By default, only administrative users can change this value.
 	VlanTransparent         *bool        `json:"vlan_transparent,omitempty" yaml:"vlan_transparent,omitempty"`                   // Indicates the VLAN transparency mode of the network, which is VLAN transparent (true) or not VLAN transparent (false).
@@ -320,8 +441,8 @@ type SubnetSpec struct {
 	Name            string   `json:"name" yaml:"name"`                                               // network name
 	EnableDHCP      *bool    `json:"enable_dhcp,omitempty" yaml:"enable_dhcp,omitempty"`             // Indicates whether dhcp is enabled or disabled for the subnet. Default is true.
 	DNSNameServers  []string `json:"dns_name_servers,omitempty" yaml:"dns_name_servers,omitempty"`   // List of dns name servers associated with the subnet.
-	AllocationPools []string `json:"allocation_pools,omitempty" yaml:"allocation_pools,omitempty"`   // Allocation pools with start and end IP addresses for this subnet. If allocation_pools are not specified, OpenStack Networking automatically allocates pools for covering all IP addresses in the CIDR, excluding the address reserved for the subnet gateway by default.
-	HostRoutes      []string `json:"host_routes,omitempty" yaml:"host_routes,omitempty"`             // Additional routes for the subnet. A list of dictionaries with destination and nexthop parameters.
+	AllocationPools []string `json:"allocation_pools,omitempty" yaml:"allocation_pools,omitempty"`   // Allocation pools (start-end) with start and end IP addresses inside the CIDR of this subnet. If allocation_pools are not specified, OpenStack Networking automatically allocates pools for covering all IP addresses in the CIDR, excluding the address reserved for the subnet gateway by default.
+	HostRoutes      []string `json:"host_routes,omitempty" yaml:"host_routes,omitempty"`             // Additional routes for the subnet (destination,nexthop). The nexthop has to be inside the CIDR of this subnet.
 	IpVersion       int      `json:"ip_version,omitempty" yaml:"ip_version,omitempty"`               // ip-version 4 or 6
 	GatewayIP       string   `json:"gateway_ip,omitempty" yaml:"gateway_ip,omitempty"`               // Gateway IP of this subnet. If the value is null that implies no gateway is associated with the subnet. If the gateway_ip is not specified, OpenStack Networking allocates an address from the CIDR for the gateway for the subnet by default.
 	CIDR            string   `json:"cidr,omitempty" yaml:"cidr,omitempty"`                           // The CIDR of the subnet.
@@ -373,6 +494,88 @@ type RouterRouteSpec struct {
 	Nexthop     string `json:"nexthop,omitempty" yaml:"nexthop,omitempty"`         // Route nexthop
 }
 
+// A neutron security group (see https://docs.openstack.org/api-ref/network/v2/index.html#security-groups-security-groups)
+type SecurityGroupSpec struct {
+	Name        string                  `json:"name" yaml:"name"`                                   // security group name
+	Description string                  `json:"description,omitempty" yaml:"description,omitempty"` // description of the security group
+	Rules       []SecurityGroupRuleSpec `json:"rules,omitempty" yaml:"rules,omitempty"`             // rules of the security group (see --prune-security-group-rules)
+}
+
+// A neutron security group rule (see https://docs.openstack.org/api-ref/network/v2/index.html#security-group-rules-security-group-rules)
+type SecurityGroupRuleSpec struct {
+	Direction      string `json:"direction" yaml:"direction"`                                   // ingress or egress
+	EtherType      string `json:"ethertype,omitempty" yaml:"ethertype,omitempty"`               // IPv4 (default) or IPv6
+	Protocol       string `json:"protocol,omitempty" yaml:"protocol,omitempty"`                 // The IP protocol name (like tcp, udp or icmp) or number. Any protocol if omitted.
+	PortRangeMin   int    `json:"port_range_min,omitempty" yaml:"port_range_min,omitempty"`     // The lowest port of the range, or the ICMP type.
+	PortRangeMax   int    `json:"port_range_max,omitempty" yaml:"port_range_max,omitempty"`     // The highest port of the range, or the ICMP code.
+	RemoteIPPrefix string `json:"remote_ip_prefix,omitempty" yaml:"remote_ip_prefix,omitempty"` // The remote CIDR the rule matches.
+	RemoteGroup    string `json:"remote_group,omitempty" yaml:"remote_group,omitempty"`         // The name of the remote security group of the project the rule matches.
+	Description    string `json:"description,omitempty" yaml:"description,omitempty"`           // description of the rule
+}
+
+// A neutron QoS policy (see https://docs.openstack.org/api-ref/network/v2/index.html#qos-policies-qos)
+type QosPolicySpec struct {
+	Name        string        `json:"name" yaml:"name"`                                   // qos policy name
+	Description string        `json:"description,omitempty" yaml:"description,omitempty"` // description of the qos policy
+	Shared      *bool         `json:"shared,omitempty" yaml:"shared,omitempty"`           // Indicates whether this policy is shared across all projects.
+	IsDefault   *bool         `json:"is_default,omitempty" yaml:"is_default,omitempty"`   // Indicates whether this policy is the default policy of the project.
+	Rules       []QosRuleSpec `json:"rules,omitempty" yaml:"rules,omitempty"`             // rules of the qos policy. Undeclared rules are deleted.
+}
+
+// A neutron QoS rule (see https://docs.openstack.org/api-ref/network/v2/index.html#quality-of-service)
+type QosRuleSpec struct {
+	Type         string `json:"type" yaml:"type"`                                         // bandwidth_limit, dscp_marking or minimum_bandwidth
+	MaxKbps      int    `json:"max_kbps,omitempty" yaml:"max_kbps,omitempty"`             // The maximum KBPS (kilobits per second) value of a bandwidth_limit rule.
+	MaxBurstKbps int    `json:"max_burst_kbps,omitempty" yaml:"max_burst_kbps,omitempty"` // The maximum burst size (in kilobits) of a bandwidth_limit rule.
+	MinKbps      int    `json:"min_kbps,omitempty" yaml:"min_kbps,omitempty"`             // The minimum KBPS (kilobits per second) value of a minimum_bandwidth rule.
+	DSCPMark     int    `json:"dscp_mark,omitempty" yaml:"dscp_mark,omitempty"`           // The DSCP mark value of a dscp_marking rule.
+	Direction    string `json:"direction,omitempty" yaml:"direction,omitempty"`           // The direction of the traffic of bandwidth_limit and minimum_bandwidth rules: egress (default) or ingress.
+}
+
+// A neutron port (see https://docs.openstack.org/api-ref/network/v2/index.html#ports)
+type PortSpec struct {
+	Name                string                   `json:"name" yaml:"name"`                                                       // port name
+	Description         string                   `json:"description,omitempty" yaml:"description,omitempty"`                     // description of the port
+	Network             string                   `json:"network" yaml:"network"`                                                 // network-name (network-name or network-name@project@domain)
+	FixedIPs            []FixedIPSpec            `json:"fixed_ips,omitempty" yaml:"fixed_ips,omitempty"`                         // The IP addresses of the port. An address is allocated from the network if omitted.
+	MACAddress          string                   `json:"mac_address,omitempty" yaml:"mac_address,omitempty"`                     // The MAC address of the port. Neutron generates one if omitted.
+	AllowedAddressPairs []AllowedAddressPairSpec `json:"allowed_address_pairs,omitempty" yaml:"allowed_address_pairs,omitempty"` // Additional IP addresses (or CIDRs) and MAC addresses the port accepts traffic for.
+	Tags                []string                 `json:"tags,omitempty" yaml:"tags,omitempty"`                                   // List of port tags (see https://developer.openstack.org/api-ref/networking/v2/index.html#tag-extension-tags)
+}
+
+// A fixed IP address of a neutron port
+type FixedIPSpec struct {
+	Subnet    string `json:"subnet" yaml:"subnet"`                             // subnet-name (subnet-name or subnet-name@project@domain)
+	IPAddress string `json:"ip_address,omitempty" yaml:"ip_address,omitempty"` // The IP address. An address is allocated from the subnet if omitted.
+}
+
+// An allowed address pair of a neutron port
+type AllowedAddressPairSpec struct {
+	IPAddress  string `json:"ip_address" yaml:"ip_address"`                       // IP address or CIDR
+	MACAddress string `json:"mac_address,omitempty" yaml:"mac_address,omitempty"` // MAC address, the MAC address of the port if omitted
+}
+
+// A neutron floating ip (see https://docs.openstack.org/api-ref/network/v2/index.html#floating-ips-floatingips)
+type FloatingIPSpec struct {
+	FloatingIPAddress string `json:"floating_ip_address" yaml:"floating_ip_address"`               // The floating IP address to allocate.
+	FloatingNetwork   string `json:"floating_network" yaml:"floating_network"`                     // external network-name (network-name or network-name@project@domain), plain names are looked up in all projects
+	Subnet            string `json:"subnet,omitempty" yaml:"subnet,omitempty"`                     // subnet-name of the external network to allocate the address from (subnet-name or subnet-name@project@domain), plain names are looked up in all projects
+	Port              string `json:"port,omitempty" yaml:"port,omitempty"`                         // name of a port of the project to associate the floating IP with
+	FixedIPAddress    string `json:"fixed_ip_address,omitempty" yaml:"fixed_ip_address,omitempty"` // The fixed IP address of the port to associate, if the port has several.
+	Description       string `json:"description,omitempty" yaml:"description,omitempty"`           // description of the floating ip
+}
+
+// A neutron network segment range (see https://docs.openstack.org/api-ref/network/v2/index.html#network-segment-ranges)
+type NetworkSegmentRangeSpec struct {
+	Name            string `json:"name" yaml:"name"`                                             // network segment range name
+	Description     string `json:"description,omitempty" yaml:"description,omitempty"`           // description of the network segment range
+	NetworkType     string `json:"network_type" yaml:"network_type"`                             // vlan, vxlan, gre or geneve
+	PhysicalNetwork string `json:"physical_network,omitempty" yaml:"physical_network,omitempty"` // The physical network of a vlan range.
+	Minimum         int    `json:"minimum" yaml:"minimum"`                                       // The lowest segmentation id of the range.
+	Maximum         int    `json:"maximum" yaml:"maximum"`                                       // The highest segmentation id of the range.
+	Project         string `json:"project,omitempty" yaml:"project,omitempty"`                   // project_name@domain_name owning the range. The range is shared by all projects if omitted.
+}
+
 // SwiftAccountSpec defines a swift account
 type SwiftAccountSpec struct {
 	Enabled    *bool                `json:"enabled,omitempty" yaml:"enabled,omitempty"`       // Create a swift account
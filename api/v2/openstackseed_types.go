@@ -39,18 +39,30 @@ type OpenstackSeedSpec struct {
 	Regions []RegionSpec `json:"regions,omitempty" yaml:"regions,omitempty"`
 	// list keystone services and their endpoints
 	Services []ServiceSpec `json:"services,omitempty" yaml:"services,omitempty"`
+	// list of keystone-to-keystone federation service providers
+	ServiceProviders []ServiceProviderSpec `json:"service_providers,omitempty" yaml:"service_providers,omitempty"`
 	// list of nova flavors
 	Flavors []FlavorSpec `json:"flavors,omitempty" yaml:"flavors,omitempty"`
+	// list of nova host aggregates
+	Aggregates []AggregateSpec `json:"aggregates,omitempty" yaml:"aggregates,omitempty"`
 	// list of Manila share types
 	ShareTypes []ShareTypeSpec `json:"share_types,omitempty" yaml:"share_types,omitempty"`
 	// list of resource classes for the placement service (currently still part of nova)
 	ResourceClasses []string `json:"resource_classes,omitempty" yaml:"resource_classes,omitempty"`
+	// list of custom traits for the placement service
+	Traits []string `json:"traits,omitempty" yaml:"traits,omitempty"`
+	// list of neutron network segment ranges, seeded before the domains
+	NetworkSegmentRanges []NetworkSegmentRangeSpec `json:"network_segment_ranges,omitempty" yaml:"network_segment_ranges,omitempty"`
+	// list of neutron qos policies owned by the project of the seeder, seeded before the domains
+	QosPolicies []QosPolicySpec `json:"qos_policies,omitempty" yaml:"qos_policies,omitempty"`
 	// list keystone domains with their configuration, users, groups, projects, etc
 	Domains []DomainSpec `json:"domains,omitempty" yaml:"domains,omitempty"`
-	// list of neutron rbac polices (currently only network rbacs are supported)
+	// list of neutron rbac policies of networks, qos policies, security groups, address scopes, address groups and subnet pools
 	RBACPolicies []RBACPolicySpec `json:"rbac_policies,omitempty" yaml:"rbac_policies,omitempty"`
 	// list of cinder volume types
 	VolumeTypes []VolumeTypeSpec `json:"volume_types,omitempty" yaml:"volume_types,omitempty"`
+	// list of cinder qos specs and their volume type associations
+	QosSpecs []QosSpecSpec `json:"qos_specs,omitempty" yaml:"qos_specs,omitempty"`
 }
 
 // OpenstackSeedStatus defines the observed state of OpenstackSeed
@@ -59,6 +71,54 @@ type OpenstackSeedStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 	UnfinishedSeeds           map[string]string `json:"unfinished_seeds,omitempty" yaml:"unfinished_seeds,omitempty"`
 	ReconciledResourceVersion string            `json:"reconciled_resource_version,omitempty" yaml:"reconciled_resource_version,omitempty"`
+	// inconsistencies between the service catalog and the services declared by all seeds (see --catalog-audit-seed)
+	CatalogFindings []CatalogFinding `json:"catalog_findings,omitempty" yaml:"catalog_findings,omitempty"`
+	// attributes of seeded resources which differ from the spec but cannot be changed in place
+	Drift []string `json:"drift,omitempty" yaml:"drift,omitempty"`
+	// the last change of every quota the seeder updated
+	QuotaChanges []QuotaChange `json:"quota_changes,omitempty" yaml:"quota_changes,omitempty"`
+	// the neutron ports seeded for the projects
+	Ports []PortStatus `json:"ports,omitempty" yaml:"ports,omitempty"`
+	// the neutron floating ips seeded for the projects
+	FloatingIPs []FloatingIPStatus `json:"floating_ips,omitempty" yaml:"floating_ips,omitempty"`
+}
+
+// PortStatus is a neutron port seeded by the seeder
+type PortStatus struct {
+	Project    string   `json:"project" yaml:"project"`                             // project_name@domain_name
+	Name       string   `json:"name" yaml:"name"`                                   // port name
+	ID         string   `json:"id" yaml:"id"`                                       // port id
+	MACAddress string   `json:"mac_address,omitempty" yaml:"mac_address,omitempty"` // allocated mac address
+	FixedIPs   []string `json:"fixed_ips,omitempty" yaml:"fixed_ips,omitempty"`     // allocated ip addresses
+}
+
+// FloatingIPStatus is a neutron floating ip seeded by the seeder
+type FloatingIPStatus struct {
+	Project           string `json:"project" yaml:"project"`                                       // project_name@domain_name
+	FloatingIPAddress string `json:"floating_ip_address" yaml:"floating_ip_address"`               // allocated floating ip address
+	ID                string `json:"id" yaml:"id"`                                                 // floating ip id
+	PortID            string `json:"port_id,omitempty" yaml:"port_id,omitempty"`                   // id of the associated port
+	FixedIPAddress    string `json:"fixed_ip_address,omitempty" yaml:"fixed_ip_address,omitempty"` // associated fixed ip address
+}
+
+// QuotaChange is a project quota updated by the seeder
+type QuotaChange struct {
+	Service  string      `json:"service" yaml:"service"`               // compute, volume, share or network
+	Project  string      `json:"project" yaml:"project"`               // project_name@domain_name
+	Resource string      `json:"resource" yaml:"resource"`             // quota key
+	Old      int64       `json:"old" yaml:"old"`                       // quota before the change
+	New      int64       `json:"new" yaml:"new"`                       // quota after the change
+	Time     metav1.Time `json:"time,omitempty" yaml:"time,omitempty"` // time of the change
+}
+
+// CatalogFinding is an inconsistency found by the service catalog audit
+type CatalogFinding struct {
+	Kind      string `json:"kind" yaml:"kind"`                               // UndeclaredService, UndeclaredEndpoint or DuplicateEndpoint
+	Service   string `json:"service" yaml:"service"`                         // service type/name
+	Region    string `json:"region,omitempty" yaml:"region,omitempty"`       // endpoint region
+	Interface string `json:"interface,omitempty" yaml:"interface,omitempty"` // endpoint interface
+	URL       string `json:"url,omitempty" yaml:"url,omitempty"`             // endpoint url
+	Message   string `json:"message,omitempty" yaml:"message,omitempty"`     // human readable description
 }
 
 //+kubebuilder:object:root=true
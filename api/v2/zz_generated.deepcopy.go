@@ -52,6 +52,94 @@ func (in *AddressScopeSpec) DeepCopy() *AddressScopeSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AggregateSpec) DeepCopyInto(out *AggregateSpec) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AggregateSpec.
+func (in *AggregateSpec) DeepCopy() *AggregateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AggregateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllowedAddressPairSpec) DeepCopyInto(out *AllowedAddressPairSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllowedAddressPairSpec.
+func (in *AllowedAddressPairSpec) DeepCopy() *AllowedAddressPairSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AllowedAddressPairSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogFinding) DeepCopyInto(out *CatalogFinding) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogFinding.
+func (in *CatalogFinding) DeepCopy() *CatalogFinding {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogFinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComputeQuotaSpec) DeepCopyInto(out *ComputeQuotaSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComputeQuotaSpec.
+func (in *ComputeQuotaSpec) DeepCopy() *ComputeQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ComputeQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialSpec) DeepCopyInto(out *CredentialSpec) {
+	*out = *in
+	out.Blob = in.Blob
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialSpec.
+func (in *CredentialSpec) DeepCopy() *CredentialSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DNSQuotaSpec) DeepCopyInto(out *DNSQuotaSpec) {
 	*out = *in
@@ -292,6 +380,21 @@ func (in *ExtraServiceSpec) DeepCopy() *ExtraServiceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FixedIPSpec) DeepCopyInto(out *FixedIPSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FixedIPSpec.
+func (in *FixedIPSpec) DeepCopy() *FixedIPSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FixedIPSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FlavorSpec) DeepCopyInto(out *FlavorSpec) {
 	*out = *in
@@ -324,6 +427,36 @@ func (in *FlavorSpec) DeepCopy() *FlavorSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FloatingIPSpec) DeepCopyInto(out *FloatingIPSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FloatingIPSpec.
+func (in *FloatingIPSpec) DeepCopy() *FloatingIPSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FloatingIPSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FloatingIPStatus) DeepCopyInto(out *FloatingIPStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FloatingIPStatus.
+func (in *FloatingIPStatus) DeepCopy() *FloatingIPStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FloatingIPStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GroupSpec) DeepCopyInto(out *GroupSpec) {
 	*out = *in
@@ -446,6 +579,21 @@ func (in *NetworkQuotaSpec) DeepCopy() *NetworkQuotaSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkSegmentRangeSpec) DeepCopyInto(out *NetworkSegmentRangeSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkSegmentRangeSpec.
+func (in *NetworkSegmentRangeSpec) DeepCopy() *NetworkSegmentRangeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkSegmentRangeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkSpec) DeepCopyInto(out *NetworkSpec) {
 	*out = *in
@@ -587,6 +735,13 @@ func (in *OpenstackSeedSpec) DeepCopyInto(out *OpenstackSeedSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ServiceProviders != nil {
+		in, out := &in.ServiceProviders, &out.ServiceProviders
+		*out = make([]ServiceProviderSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Flavors != nil {
 		in, out := &in.Flavors, &out.Flavors
 		*out = make([]FlavorSpec, len(*in))
@@ -594,6 +749,13 @@ func (in *OpenstackSeedSpec) DeepCopyInto(out *OpenstackSeedSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Aggregates != nil {
+		in, out := &in.Aggregates, &out.Aggregates
+		*out = make([]AggregateSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.ShareTypes != nil {
 		in, out := &in.ShareTypes, &out.ShareTypes
 		*out = make([]ShareTypeSpec, len(*in))
@@ -606,6 +768,23 @@ func (in *OpenstackSeedSpec) DeepCopyInto(out *OpenstackSeedSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Traits != nil {
+		in, out := &in.Traits, &out.Traits
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NetworkSegmentRanges != nil {
+		in, out := &in.NetworkSegmentRanges, &out.NetworkSegmentRanges
+		*out = make([]NetworkSegmentRangeSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.QosPolicies != nil {
+		in, out := &in.QosPolicies, &out.QosPolicies
+		*out = make([]QosPolicySpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Domains != nil {
 		in, out := &in.Domains, &out.Domains
 		*out = make([]DomainSpec, len(*in))
@@ -625,6 +804,13 @@ func (in *OpenstackSeedSpec) DeepCopyInto(out *OpenstackSeedSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.QosSpecs != nil {
+		in, out := &in.QosSpecs, &out.QosSpecs
+		*out = make([]QosSpecSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackSeedSpec.
@@ -647,6 +833,35 @@ func (in *OpenstackSeedStatus) DeepCopyInto(out *OpenstackSeedStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.CatalogFindings != nil {
+		in, out := &in.CatalogFindings, &out.CatalogFindings
+		*out = make([]CatalogFinding, len(*in))
+		copy(*out, *in)
+	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.QuotaChanges != nil {
+		in, out := &in.QuotaChanges, &out.QuotaChanges
+		*out = make([]QuotaChange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]PortStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FloatingIPs != nil {
+		in, out := &in.FloatingIPs, &out.FloatingIPs
+		*out = make([]FloatingIPStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackSeedStatus.
@@ -659,6 +874,56 @@ func (in *OpenstackSeedStatus) DeepCopy() *OpenstackSeedStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PortSpec) DeepCopyInto(out *PortSpec) {
+	*out = *in
+	if in.FixedIPs != nil {
+		in, out := &in.FixedIPs, &out.FixedIPs
+		*out = make([]FixedIPSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedAddressPairs != nil {
+		in, out := &in.AllowedAddressPairs, &out.AllowedAddressPairs
+		*out = make([]AllowedAddressPairSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PortSpec.
+func (in *PortSpec) DeepCopy() *PortSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PortSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PortStatus) DeepCopyInto(out *PortStatus) {
+	*out = *in
+	if in.FixedIPs != nil {
+		in, out := &in.FixedIPs, &out.FixedIPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PortStatus.
+func (in *PortStatus) DeepCopy() *PortStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PortStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProjectEndpointSpec) DeepCopyInto(out *ProjectEndpointSpec) {
 	*out = *in
@@ -704,11 +969,40 @@ func (in *ProjectSpec) DeepCopyInto(out *ProjectSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ComputeQuota != nil {
+		in, out := &in.ComputeQuota, &out.ComputeQuota
+		*out = new(ComputeQuotaSpec)
+		**out = **in
+	}
+	if in.VolumeQuota != nil {
+		in, out := &in.VolumeQuota, &out.VolumeQuota
+		*out = new(VolumeQuotaSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ShareTypes != nil {
 		in, out := &in.ShareTypes, &out.ShareTypes
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ShareQuota != nil {
+		in, out := &in.ShareQuota, &out.ShareQuota
+		*out = new(ShareQuotaSpec)
+		**out = **in
+	}
+	if in.SecurityServices != nil {
+		in, out := &in.SecurityServices, &out.SecurityServices
+		*out = make([]SecurityServiceSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ShareNetworks != nil {
+		in, out := &in.ShareNetworks, &out.ShareNetworks
+		*out = make([]ShareNetworkSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.AddressScopes != nil {
 		in, out := &in.AddressScopes, &out.AddressScopes
 		*out = make([]AddressScopeSpec, len(*in))
@@ -742,6 +1036,32 @@ func (in *ProjectSpec) DeepCopyInto(out *ProjectSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SecurityGroups != nil {
+		in, out := &in.SecurityGroups, &out.SecurityGroups
+		*out = make([]SecurityGroupSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.QosPolicies != nil {
+		in, out := &in.QosPolicies, &out.QosPolicies
+		*out = make([]QosPolicySpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]PortSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FloatingIPs != nil {
+		in, out := &in.FloatingIPs, &out.FloatingIPs
+		*out = make([]FloatingIPSpec, len(*in))
+		copy(*out, *in)
+	}
 	if in.Swift != nil {
 		in, out := &in.Swift, &out.Swift
 		*out = new(SwiftAccountSpec)
@@ -781,6 +1101,94 @@ func (in *ProjectSpec) DeepCopy() *ProjectSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QosPolicySpec) DeepCopyInto(out *QosPolicySpec) {
+	*out = *in
+	if in.Shared != nil {
+		in, out := &in.Shared, &out.Shared
+		*out = new(bool)
+		**out = **in
+	}
+	if in.IsDefault != nil {
+		in, out := &in.IsDefault, &out.IsDefault
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]QosRuleSpec, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QosPolicySpec.
+func (in *QosPolicySpec) DeepCopy() *QosPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QosPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QosRuleSpec) DeepCopyInto(out *QosRuleSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QosRuleSpec.
+func (in *QosRuleSpec) DeepCopy() *QosRuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QosRuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QosSpecSpec) DeepCopyInto(out *QosSpecSpec) {
+	*out = *in
+	if in.Specs != nil {
+		in, out := &in.Specs, &out.Specs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.VolumeTypes != nil {
+		in, out := &in.VolumeTypes, &out.VolumeTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QosSpecSpec.
+func (in *QosSpecSpec) DeepCopy() *QosSpecSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QosSpecSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaChange) DeepCopyInto(out *QuotaChange) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaChange.
+func (in *QuotaChange) DeepCopy() *QuotaChange {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RBACPolicySpec) DeepCopyInto(out *RBACPolicySpec) {
 	*out = *in
@@ -936,6 +1344,96 @@ func (in *RouterSpec) DeepCopy() *RouterSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyRef) DeepCopyInto(out *SecretKeyRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyRef.
+func (in *SecretKeyRef) DeepCopy() *SecretKeyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityGroupRuleSpec) DeepCopyInto(out *SecurityGroupRuleSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityGroupRuleSpec.
+func (in *SecurityGroupRuleSpec) DeepCopy() *SecurityGroupRuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityGroupRuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityGroupSpec) DeepCopyInto(out *SecurityGroupSpec) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]SecurityGroupRuleSpec, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityGroupSpec.
+func (in *SecurityGroupSpec) DeepCopy() *SecurityGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityServiceSpec) DeepCopyInto(out *SecurityServiceSpec) {
+	*out = *in
+	if in.Password != nil {
+		in, out := &in.Password, &out.Password
+		*out = new(SecretKeyRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityServiceSpec.
+func (in *SecurityServiceSpec) DeepCopy() *SecurityServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceProviderSpec) DeepCopyInto(out *ServiceProviderSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceProviderSpec.
+func (in *ServiceProviderSpec) DeepCopy() *ServiceProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceSpec) DeepCopyInto(out *ServiceSpec) {
 	*out = *in
@@ -956,6 +1454,41 @@ func (in *ServiceSpec) DeepCopy() *ServiceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShareNetworkSpec) DeepCopyInto(out *ShareNetworkSpec) {
+	*out = *in
+	if in.SecurityServices != nil {
+		in, out := &in.SecurityServices, &out.SecurityServices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShareNetworkSpec.
+func (in *ShareNetworkSpec) DeepCopy() *ShareNetworkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ShareNetworkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShareQuotaSpec) DeepCopyInto(out *ShareQuotaSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShareQuotaSpec.
+func (in *ShareQuotaSpec) DeepCopy() *ShareQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ShareQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ShareTypeSpec) DeepCopyInto(out *ShareTypeSpec) {
 	*out = *in
@@ -1152,6 +1685,11 @@ func (in *UserSpec) DeepCopyInto(out *UserSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = make([]CredentialSpec, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserSpec.
@@ -1164,6 +1702,43 @@ func (in *UserSpec) DeepCopy() *UserSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeQuotaSpec) DeepCopyInto(out *VolumeQuotaSpec) {
+	*out = *in
+	if in.VolumeTypes != nil {
+		in, out := &in.VolumeTypes, &out.VolumeTypes
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeQuotaSpec.
+func (in *VolumeQuotaSpec) DeepCopy() *VolumeQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeTypeEncryptionSpec) DeepCopyInto(out *VolumeTypeEncryptionSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeTypeEncryptionSpec.
+func (in *VolumeTypeEncryptionSpec) DeepCopy() *VolumeTypeEncryptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeTypeEncryptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeTypeSpec) DeepCopyInto(out *VolumeTypeSpec) {
 	*out = *in
@@ -1179,6 +1754,16 @@ func (in *VolumeTypeSpec) DeepCopyInto(out *VolumeTypeSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Projects != nil {
+		in, out := &in.Projects, &out.Projects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(VolumeTypeEncryptionSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeTypeSpec.
@@ -22,4 +22,12 @@ type Options struct {
 	EnableLeaderElection    bool
 	ProbeAddr               string
 	MaxConcurrentReconciles int
+	CatalogAuditSeed        string
+	RecreateFlavors         bool
+	PruneFlavorAccess       bool
+	PruneShareTypeAccess    bool
+	PruneVolumeTypeAccess   bool
+	PruneResourceClasses    bool
+	PruneSecurityGroupRules bool
+	PruneRBACPolicies       bool
 }
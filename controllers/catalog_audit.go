@@ -0,0 +1,75 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+	"github.com/sapcc/openstack-seeder/openstack"
+)
+
+// auditCatalog compares the service catalog against the services declared by all seeds.
+// The findings are published in the seed status and as metrics. Events are only emitted for
+// findings that were not in the status before, so a steady state does not flood the events.
+func (r *OpenstackSeedReconciler) auditCatalog(ctx context.Context, seed *openstackstablesapccv2.OpenstackSeed) error {
+	var seeds openstackstablesapccv2.OpenstackSeedList
+	if err := r.List(ctx, &seeds); err != nil {
+		return err
+	}
+	var declared []openstackstablesapccv2.ServiceSpec
+	for _, s := range seeds.Items {
+		declared = append(declared, s.Spec.Services...)
+	}
+	c, err := openstack.NewIdentityClient()
+	if err != nil {
+		return err
+	}
+	findings, err := openstack.NewKeystone(c).AuditCatalog(declared)
+	if err != nil {
+		return err
+	}
+	for _, f := range newCatalogFindings(seed.Status.CatalogFindings, findings) {
+		r.Recorder.Event(seed, corev1.EventTypeWarning, f.Kind, f.Message)
+	}
+	seed.Status.CatalogFindings = findings
+
+	count := make(map[string]float64)
+	for _, f := range findings {
+		count[f.Kind]++
+	}
+	for _, kind := range openstack.CatalogFindingKinds {
+		catalogFindings.WithLabelValues(kind).Set(count[kind])
+	}
+	return nil
+}
+
+// newCatalogFindings returns the findings that are not in the previous findings.
+func newCatalogFindings(previous, findings []openstackstablesapccv2.CatalogFinding) (added []openstackstablesapccv2.CatalogFinding) {
+	known := make(map[openstackstablesapccv2.CatalogFinding]bool)
+	for _, f := range previous {
+		known[f] = true
+	}
+	for _, f := range findings {
+		if !known[f] {
+			added = append(added, f)
+		}
+	}
+	return
+}
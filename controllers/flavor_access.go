@@ -0,0 +1,123 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+	"github.com/sapcc/openstack-seeder/openstack"
+)
+
+// seedFlavorAccess seeds the access lists of the private flavors of the seed and of the flavors
+// referenced by its projects. The access list of a flavor is computed from all seeds, so a seed
+// never revokes the access granted by another one.
+func (r *OpenstackSeedReconciler) seedFlavorAccess(ctx context.Context, seed *openstackstablesapccv2.OpenstackSeed) (err error) {
+	access, err := r.projectAccess(ctx, seed, privateFlavorIDs(seed.Spec.Flavors), func(p openstackstablesapccv2.ProjectSpec) []string { return p.Flavors })
+	if err != nil || len(access) == 0 {
+		return
+	}
+	cc, err := openstack.NewComputeClient()
+	if err != nil {
+		return
+	}
+	n := openstack.NewNova(cc)
+	for f, projectIDs := range access {
+		if err = n.SeedFlavorAccess(f, projectIDs, r.opts.PruneFlavorAccess); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// privateFlavorIDs returns the ids of the private flavors.
+func privateFlavorIDs(flavors []openstackstablesapccv2.FlavorSpec) (ids []string) {
+	for _, f := range flavors {
+		if f.IsPublic != nil && !*f.IsPublic {
+			ids = append(ids, f.Id)
+		}
+	}
+	return
+}
+
+// projectAccess maps the given resources and the resources (e.g. flavors) referenced by the projects
+// of the seed to the ids of all projects referencing them in any seed. Resources no project references
+// anymore map to no project, so their access can be revoked.
+func (r *OpenstackSeedReconciler) projectAccess(ctx context.Context, seed *openstackstablesapccv2.OpenstackSeed,
+	owned []string, resources func(openstackstablesapccv2.ProjectSpec) []string) (access map[string][]string, err error) {
+	access = make(map[string][]string)
+	for _, res := range owned {
+		access[res] = nil
+	}
+	for _, d := range seed.Spec.Domains {
+		for _, p := range d.Projects {
+			for _, res := range resources(p) {
+				access[res] = nil
+			}
+		}
+	}
+	if len(access) == 0 {
+		return
+	}
+	var seeds openstackstablesapccv2.OpenstackSeedList
+	if err = r.List(ctx, &seeds); err != nil {
+		return
+	}
+	ic, err := openstack.NewIdentityClient()
+	if err != nil {
+		return
+	}
+	k := openstack.NewKeystone(ic)
+	for _, s := range seeds.Items {
+		own := s.Namespace == seed.Namespace && s.Name == seed.Name
+		for _, d := range s.Spec.Domains {
+			for _, p := range d.Projects {
+				var projectID string
+				for _, res := range resources(p) {
+					if _, ok := access[res]; !ok {
+						continue
+					}
+					if projectID == "" {
+						id, ok, err := seedProjectID(k, own, p.Name+"@"+d.Name)
+						if err != nil {
+							return nil, err
+						}
+						if !ok {
+							break
+						}
+						projectID = id
+					}
+					access[res] = append(access[res], projectID)
+				}
+			}
+		}
+	}
+	return
+}
+
+// seedProjectID resolves the id of a project (project_name@domain_name) of a seed. Projects of
+// other seeds might not be seeded yet and cannot have access to anything anyway, so they are
+// skipped (ok is false, err is nil) if they cannot be found.
+func seedProjectID(k *openstack.Keystone, own bool, name string) (id string, ok bool, err error) {
+	if id, err = k.GetProjectIDByName(name); err != nil {
+		if own || !openstack.IsNotFound(err) {
+			return "", false, err
+		}
+		return "", false, nil
+	}
+	return id, true, nil
+}
@@ -0,0 +1,123 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+	"github.com/sapcc/openstack-seeder/openstack"
+)
+
+func (r *OpenstackSeedReconciler) seedShareTypes(seed *openstackstablesapccv2.OpenstackSeed) (err error) {
+	if len(seed.Spec.ShareTypes) == 0 {
+		return
+	}
+	for _, st := range seed.Spec.ShareTypes {
+		if err = openstack.ValidateShareType(st); err != nil {
+			return
+		}
+	}
+	c, err := openstack.NewSharedFileSystemClient()
+	if err != nil {
+		return
+	}
+	m := openstack.NewManila(c)
+	for _, st := range seed.Spec.ShareTypes {
+		_, drift, err := m.SeedShareType(st)
+		r.recordDrift(seed, "ShareTypeDrift", drift)
+		if err != nil {
+			return err
+		}
+	}
+	return
+}
+
+// seedShareTypeAccess seeds the access lists of the share types referenced by the projects of the seed.
+// Like flavor access, the access list of a share type is computed from all seeds.
+func (r *OpenstackSeedReconciler) seedShareTypeAccess(ctx context.Context, seed *openstackstablesapccv2.OpenstackSeed) (err error) {
+	access, err := r.projectAccess(ctx, seed, nil, func(p openstackstablesapccv2.ProjectSpec) []string { return p.ShareTypes })
+	if err != nil || len(access) == 0 {
+		return
+	}
+	c, err := openstack.NewSharedFileSystemClient()
+	if err != nil {
+		return
+	}
+	m := openstack.NewManila(c)
+	for st, projectIDs := range access {
+		if err = m.SeedShareTypeAccess(st, projectIDs, r.opts.PruneShareTypeAccess); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// seedShareNetworks seeds the security services and share networks of the projects. Both are
+// created with a client scoped to the project, because manila creates them in the project of the token.
+func (r *OpenstackSeedReconciler) seedShareNetworks(ctx context.Context, seed *openstackstablesapccv2.OpenstackSeed) error {
+	var neutron *openstack.Neutron
+	return seedProjects(seed,
+		func(p openstackstablesapccv2.ProjectSpec) bool {
+			return len(p.SecurityServices) > 0 || len(p.ShareNetworks) > 0
+		},
+		func(d openstackstablesapccv2.DomainSpec, p openstackstablesapccv2.ProjectSpec) (err error) {
+			for _, ss := range p.SecurityServices {
+				if err = openstack.ValidateSecurityService(ss); err != nil {
+					return
+				}
+			}
+			return
+		},
+		neutronConnector(&neutron),
+		func(projectID string, d openstackstablesapccv2.DomainSpec, p openstackstablesapccv2.ProjectSpec) error {
+			c, err := openstack.NewProjectSharedFileSystemClient(projectID)
+			if err != nil {
+				return err
+			}
+			m := openstack.NewManila(c)
+			for _, ss := range p.SecurityServices {
+				var password string
+				if ss.Password != nil {
+					if password, err = r.getSecretValue(ctx, seed.Namespace, *ss.Password); err != nil {
+						return err
+					}
+				}
+				_, drift, err := m.SeedSecurityService(ss, password)
+				r.recordDrift(seed, "SecurityServiceDrift", drift)
+				if err != nil {
+					return err
+				}
+			}
+			for _, sn := range p.ShareNetworks {
+				networkID, err := neutron.GetNetworkID(sn.Network, projectID)
+				if err != nil {
+					return err
+				}
+				subnetID, err := neutron.GetSubnetID(sn.Subnet, projectID)
+				if err != nil {
+					return err
+				}
+				_, drift, err := m.SeedShareNetwork(sn, networkID, subnetID)
+				r.recordDrift(seed, "ShareNetworkDrift", drift)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+}
@@ -0,0 +1,386 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+	"github.com/sapcc/openstack-seeder/openstack"
+)
+
+// seedQosPolicies seeds the qos policies of the seed, which are owned by the project of the seeder.
+func (r *OpenstackSeedReconciler) seedQosPolicies(seed *openstackstablesapccv2.OpenstackSeed) (err error) {
+	if len(seed.Spec.QosPolicies) == 0 {
+		return
+	}
+	for _, q := range seed.Spec.QosPolicies {
+		if err = openstack.ValidateQosPolicy(q); err != nil {
+			return
+		}
+	}
+	neutron, err := newNeutron()
+	if err != nil {
+		return
+	}
+	// without the project, policies of the same name in other projects would be taken over
+	projectID, err := neutron.Keystone.GetTokenProjectID()
+	if err != nil {
+		return
+	}
+	for _, q := range seed.Spec.QosPolicies {
+		if _, err = neutron.SeedQosPolicy(projectID, q); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// seedProjectQosPolicies seeds the qos policies of the projects, so that their networks can reference them.
+func (r *OpenstackSeedReconciler) seedProjectQosPolicies(seed *openstackstablesapccv2.OpenstackSeed) error {
+	var neutron *openstack.Neutron
+	return seedProjects(seed,
+		func(p openstackstablesapccv2.ProjectSpec) bool { return len(p.QosPolicies) > 0 },
+		func(d openstackstablesapccv2.DomainSpec, p openstackstablesapccv2.ProjectSpec) (err error) {
+			for _, q := range p.QosPolicies {
+				if err = openstack.ValidateQosPolicy(q); err != nil {
+					return
+				}
+			}
+			return
+		},
+		neutronConnector(&neutron),
+		func(projectID string, d openstackstablesapccv2.DomainSpec, p openstackstablesapccv2.ProjectSpec) (err error) {
+			for _, q := range p.QosPolicies {
+				if _, err = neutron.SeedQosPolicy(projectID, q); err != nil {
+					return
+				}
+			}
+			return
+		})
+}
+
+// seedSubnetPools seeds the address scopes of the projects including their subnet pools first,
+// then the subnet pools of the projects, so that subnets can be allocated from them.
+func (r *OpenstackSeedReconciler) seedSubnetPools(seed *openstackstablesapccv2.OpenstackSeed) error {
+	var neutron *openstack.Neutron
+	return seedProjects(seed,
+		func(p openstackstablesapccv2.ProjectSpec) bool {
+			return len(p.AddressScopes) > 0 || len(p.SubnetPools) > 0
+		},
+		func(d openstackstablesapccv2.DomainSpec, p openstackstablesapccv2.ProjectSpec) (err error) {
+			for _, s := range p.AddressScopes {
+				if err = openstack.ValidateAddressScope(s); err != nil {
+					return
+				}
+			}
+			for _, pool := range p.SubnetPools {
+				if err = openstack.ValidateSubnetPool(pool); err != nil {
+					return
+				}
+			}
+			return
+		},
+		neutronConnector(&neutron),
+		func(projectID string, d openstackstablesapccv2.DomainSpec, p openstackstablesapccv2.ProjectSpec) error {
+			for _, s := range p.AddressScopes {
+				_, drift, err := neutron.SeedAddressScope(projectID, s)
+				r.recordDrift(seed, "AddressScopeDrift", drift)
+				if err != nil {
+					return err
+				}
+			}
+			for _, pool := range p.SubnetPools {
+				drift, err := neutron.SeedSubnetPool(projectID, pool)
+				r.recordDrift(seed, "SubnetPoolDrift", drift)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+}
+
+// seedNetworkSegmentRanges seeds the network segment ranges of the seed.
+func (r *OpenstackSeedReconciler) seedNetworkSegmentRanges(seed *openstackstablesapccv2.OpenstackSeed) (err error) {
+	if len(seed.Spec.NetworkSegmentRanges) == 0 {
+		return
+	}
+	for _, s := range seed.Spec.NetworkSegmentRanges {
+		if err = openstack.ValidateNetworkSegmentRange(s); err != nil {
+			return
+		}
+	}
+	neutron, err := newNeutron()
+	if err != nil {
+		return
+	}
+	for _, s := range seed.Spec.NetworkSegmentRanges {
+		drift, err := neutron.SeedNetworkSegmentRange(s)
+		r.recordDrift(seed, "NetworkSegmentRangeDrift", drift)
+		if err != nil {
+			return err
+		}
+	}
+	return
+}
+
+// networkSegmentRanges returns the network segment ranges declared by all seeds.
+func (r *OpenstackSeedReconciler) networkSegmentRanges(ctx context.Context, seed *openstackstablesapccv2.OpenstackSeed) (ranges []openstackstablesapccv2.NetworkSegmentRangeSpec, err error) {
+	ranges = append(ranges, seed.Spec.NetworkSegmentRanges...)
+	var seeds openstackstablesapccv2.OpenstackSeedList
+	if err = r.List(ctx, &seeds); err != nil {
+		return
+	}
+	for _, s := range seeds.Items {
+		if s.Namespace == seed.Namespace && s.Name == seed.Name {
+			continue
+		}
+		ranges = append(ranges, s.Spec.NetworkSegmentRanges...)
+	}
+	return
+}
+
+// seedNetworks seeds the networks and subnets of the projects. Provider segmentation ids have to lie
+// in the network segment ranges declared by any seed.
+func (r *OpenstackSeedReconciler) seedNetworks(ctx context.Context, seed *openstackstablesapccv2.OpenstackSeed) error {
+	var ranges []openstackstablesapccv2.NetworkSegmentRangeSpec
+	listed := false
+	var neutron *openstack.Neutron
+	return seedProjects(seed,
+		func(p openstackstablesapccv2.ProjectSpec) bool { return len(p.Networks) > 0 },
+		func(d openstackstablesapccv2.DomainSpec, p openstackstablesapccv2.ProjectSpec) (err error) {
+			for _, n := range p.Networks {
+				if err = openstack.ValidateNetwork(n); err != nil {
+					return
+				}
+				if n.ProviderSegmentationId == "" {
+					continue
+				}
+				if !listed {
+					if ranges, err = r.networkSegmentRanges(ctx, seed); err != nil {
+						return
+					}
+					listed = true
+				}
+				if err = openstack.ValidateSegmentationID(n, p.Name+"@"+d.Name, ranges); err != nil {
+					return
+				}
+			}
+			return
+		},
+		neutronConnector(&neutron),
+		func(projectID string, d openstackstablesapccv2.DomainSpec, p openstackstablesapccv2.ProjectSpec) error {
+			for _, n := range p.Networks {
+				_, drift, err := neutron.SeedNetwork(projectID, n)
+				r.recordDrift(seed, "NetworkDrift", drift)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+}
+
+// seedRouters seeds the routers of the projects. Router interfaces may reference subnets of all projects,
+// so this runs after the networks of all projects have been seeded.
+func (r *OpenstackSeedReconciler) seedRouters(seed *openstackstablesapccv2.OpenstackSeed) error {
+	var neutron *openstack.Neutron
+	return seedProjects(seed,
+		func(p openstackstablesapccv2.ProjectSpec) bool { return len(p.Routers) > 0 },
+		nil,
+		neutronConnector(&neutron),
+		func(projectID string, d openstackstablesapccv2.DomainSpec, p openstackstablesapccv2.ProjectSpec) error {
+			for _, router := range p.Routers {
+				_, drift, err := neutron.SeedRouter(projectID, router)
+				r.recordDrift(seed, "RouterDrift", drift)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+}
+
+// seedSecurityGroups seeds the security groups of the projects. With --prune-security-group-rules undeclared rules of the security groups are deleted.
+func (r *OpenstackSeedReconciler) seedSecurityGroups(seed *openstackstablesapccv2.OpenstackSeed) error {
+	var neutron *openstack.Neutron
+	return seedProjects(seed,
+		func(p openstackstablesapccv2.ProjectSpec) bool { return len(p.SecurityGroups) > 0 },
+		func(d openstackstablesapccv2.DomainSpec, p openstackstablesapccv2.ProjectSpec) (err error) {
+			for _, g := range p.SecurityGroups {
+				if err = openstack.ValidateSecurityGroup(g); err != nil {
+					return
+				}
+			}
+			return
+		},
+		neutronConnector(&neutron),
+		func(projectID string, d openstackstablesapccv2.DomainSpec, p openstackstablesapccv2.ProjectSpec) error {
+			return neutron.SeedSecurityGroups(projectID, p.SecurityGroups, r.opts.PruneSecurityGroupRules)
+		})
+}
+
+// seedPorts seeds the ports and then the floating ips of the projects, which may be associated with the ports.
+// Floating ips can only be associated once the routers connect the ports to the external network.
+// The allocated ids and addresses are published in the seed status.
+func (r *OpenstackSeedReconciler) seedPorts(seed *openstackstablesapccv2.OpenstackSeed) (err error) {
+	var neutron *openstack.Neutron
+	var portStatus []openstackstablesapccv2.PortStatus
+	var fipStatus []openstackstablesapccv2.FloatingIPStatus
+	err = seedProjects(seed,
+		func(p openstackstablesapccv2.ProjectSpec) bool { return len(p.Ports) > 0 || len(p.FloatingIPs) > 0 },
+		func(d openstackstablesapccv2.DomainSpec, p openstackstablesapccv2.ProjectSpec) (err error) {
+			for _, port := range p.Ports {
+				if err = openstack.ValidatePort(port); err != nil {
+					return
+				}
+			}
+			for _, fip := range p.FloatingIPs {
+				if err = openstack.ValidateFloatingIP(fip); err != nil {
+					return
+				}
+			}
+			return
+		},
+		neutronConnector(&neutron),
+		func(projectID string, d openstackstablesapccv2.DomainSpec, p openstackstablesapccv2.ProjectSpec) error {
+			project := p.Name + "@" + d.Name
+			for _, spec := range p.Ports {
+				port, drift, err := neutron.SeedPort(projectID, spec)
+				r.recordDrift(seed, "PortDrift", drift)
+				if err != nil {
+					return err
+				}
+				status := openstackstablesapccv2.PortStatus{Project: project, Name: port.Name, ID: port.ID, MACAddress: port.MACAddress}
+				for _, ip := range port.FixedIPs {
+					status.FixedIPs = append(status.FixedIPs, ip.IPAddress)
+				}
+				portStatus = append(portStatus, status)
+			}
+			for _, spec := range p.FloatingIPs {
+				fip, drift, err := neutron.SeedFloatingIP(projectID, spec)
+				r.recordDrift(seed, "FloatingIPDrift", drift)
+				if err != nil {
+					return err
+				}
+				fipStatus = append(fipStatus, openstackstablesapccv2.FloatingIPStatus{
+					Project:           project,
+					FloatingIPAddress: fip.FloatingIP,
+					ID:                fip.ID,
+					PortID:            fip.PortID,
+					FixedIPAddress:    fip.FixedIP,
+				})
+			}
+			return nil
+		})
+	if err != nil {
+		return
+	}
+	seed.Status.Ports, seed.Status.FloatingIPs = portStatus, fipStatus
+	return
+}
+
+// rbacKey identifies the RBAC policies of an action on an object.
+type rbacKey struct {
+	objectType, objectID, action string
+}
+
+// seedRBACPolicies seeds the RBAC policies of the objects referenced by the seed. The targets of an object
+// are computed from all seeds, so a seed never revokes the access granted by another one. With --prune-rbac-policies
+// RBAC policies no seed declares are deleted. RBAC policies of objects no seed references are left alone, as are
+// those of objects whose policies in other seeds cannot be resolved.
+func (r *OpenstackSeedReconciler) seedRBACPolicies(ctx context.Context, seed *openstackstablesapccv2.OpenstackSeed) (err error) {
+	if len(seed.Spec.RBACPolicies) == 0 {
+		return
+	}
+	for _, p := range seed.Spec.RBACPolicies {
+		if err = openstack.ValidateRBACPolicy(p); err != nil {
+			return
+		}
+	}
+	neutron, err := newNeutron()
+	if err != nil {
+		return
+	}
+	targets := make(map[rbacKey][]string)
+	names := make(map[string]bool)
+	resolve := func(p openstackstablesapccv2.RBACPolicySpec) (key rbacKey, targetID string, err error) {
+		key = rbacKey{objectType: p.ObjectType, action: p.Action}
+		if key.objectID, err = neutron.GetRBACObjectID(p.ObjectType, p.ObjectName); err != nil {
+			return
+		}
+		targetID, err = neutron.GetRBACTargetID(p.TargetTenantName)
+		return
+	}
+	objectNames := make(map[rbacKey]string)
+	unresolved := make(map[string]bool)
+	for _, p := range seed.Spec.RBACPolicies {
+		key, targetID, err := resolve(p)
+		if err != nil {
+			return err
+		}
+		targets[key] = append(targets[key], targetID)
+		name := p.ObjectType + "/" + strings.Split(p.ObjectName, "@")[0]
+		names[name] = true
+		objectNames[key] = name
+	}
+
+	var seeds openstackstablesapccv2.OpenstackSeedList
+	if err = r.List(ctx, &seeds); err != nil {
+		return
+	}
+	for _, s := range seeds.Items {
+		if s.Namespace == seed.Namespace && s.Name == seed.Name {
+			continue
+		}
+		for _, p := range s.Spec.RBACPolicies {
+			// only objects of the same name can be the ones of this seed
+			name := p.ObjectType + "/" + strings.Split(p.ObjectName, "@")[0]
+			if !names[name] {
+				continue
+			}
+			key, targetID, err := resolve(p)
+			if err != nil {
+				// objects and projects of other seeds might not be seeded yet and cannot be granted anyway,
+				// but if the lookup failed otherwise, the policy might be one of the objects of this seed
+				if !openstack.IsNotFound(err) {
+					log.FromContext(ctx).Error(err, "cannot resolve rbac policy, undeclared ones are kept", "seed", s.Namespace+"/"+s.Name, "object", name)
+					unresolved[name] = true
+				}
+				continue
+			}
+			if _, ok := targets[key]; ok {
+				targets[key] = append(targets[key], targetID)
+			}
+		}
+	}
+
+	for key, targetIDs := range targets {
+		revoked, err := neutron.SeedRBACPolicies(key.objectType, key.objectID, key.action, targetIDs, r.opts.PruneRBACPolicies && !unresolved[objectNames[key]])
+		for _, t := range revoked {
+			log.FromContext(ctx).Info("deleted undeclared rbac policy", "object_type", key.objectType, "object_id", key.objectID, "action", key.action, "target_tenant", t)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return
+}
@@ -25,9 +25,13 @@ var (
 	seedStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "seed_status",
 	}, []string{"name"})
+	catalogFindings = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "seed_catalog_findings",
+		Help: "Number of service catalog inconsistencies found by the last catalog audit.",
+	}, []string{"kind"})
 )
 
 func init() {
 	// Register custom metrics with the global prometheus registry
-	metrics.Registry.MustRegister(seedStatus)
+	metrics.Registry.MustRegister(seedStatus, catalogFindings)
 }
@@ -19,16 +19,18 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"reflect"
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
 	"github.com/sapcc/openstack-seeder/config/options"
@@ -38,12 +40,16 @@ import (
 // OpenstackSeedReconciler reconciles a OpenstackSeed object
 type OpenstackSeedReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	opts     options.Options
 }
 
 //+kubebuilder:rbac:groups=openstack.stable.sap.cc,resources=openstackseeds,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=openstack.stable.sap.cc,resources=openstackseeds/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=openstack.stable.sap.cc,resources=openstackseeds/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -69,37 +75,48 @@ func (r *OpenstackSeedReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		// One of the dependeny seeds has not been reconciled since it changed. So we wait...
 		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
 	}
-	r.reconcileSeeds(seed)
+	r.reconcileSeeds(ctx, &seed)
+	// the findings are global, so only the designated seed publishes them
+	if r.opts.CatalogAuditSeed == req.NamespacedName.String() {
+		if err := r.auditCatalog(ctx, &seed); err != nil {
+			l.Error(err, "unable to audit the service catalog")
+		}
+	}
+	result := ctrl.Result{RequeueAfter: 24 * time.Hour}
 	if len(seed.Status.UnfinishedSeeds) > 0 {
 		seedStatus.WithLabelValues(seed.Name).Set(0)
-		return ctrl.Result{RequeueAfter: 10 * time.Minute}, nil
+		result = ctrl.Result{RequeueAfter: 10 * time.Minute}
+	} else {
+		seed.Status.ReconciledResourceVersion = seed.ResourceVersion
+		seedStatus.WithLabelValues(seed.Name).Set(1)
 	}
-	seed.Status.ReconciledResourceVersion = seed.ResourceVersion
-	seedStatus.WithLabelValues(seed.Name).Set(1)
-	return ctrl.Result{RequeueAfter: 24 * time.Hour}, nil
+	if err := r.Status().Update(ctx, &seed); err != nil {
+		l.Error(err, "unable to update OpenstackSeed status")
+		return ctrl.Result{}, err
+	}
+	return result, nil
 }
 
-func (r *OpenstackSeedReconciler) reconcileSeeds(seed openstackstablesapccv2.OpenstackSeed) error {
-	v := reflect.ValueOf(seed)
+// reconcileSeeds runs the seed steps. Every step that fails is recorded under its own name in the
+// unfinished seeds, and if steps failed before, only those are retried.
+func (r *OpenstackSeedReconciler) reconcileSeeds(ctx context.Context, seed *openstackstablesapccv2.OpenstackSeed) error {
+	retry := len(seed.Status.UnfinishedSeeds) > 0
+	if seed.Status.UnfinishedSeeds == nil {
+		seed.Status.UnfinishedSeeds = make(map[string]string)
+	}
+	if !retry {
+		seed.Status.Drift = nil
+	}
 	completed := true
-	if len(seed.Status.UnfinishedSeeds) > 0 {
-		for i := 0; i < v.NumField(); i++ {
-			if _, ok := seed.Status.UnfinishedSeeds[v.Field(i).Type().Name()]; ok {
-				err := r.reconcileSeed(v.Field(i).Type().Name(), seed)
-				if err == nil {
-					delete(seed.Status.UnfinishedSeeds, v.Field(i).Type().Name())
-				} else {
-					completed = false
-				}
-			}
+	for _, step := range r.seedSteps(ctx, seed) {
+		if _, ok := seed.Status.UnfinishedSeeds[step.name]; retry && !ok {
+			continue
 		}
-	} else {
-		for i := 0; i < v.NumField(); i++ {
-			err := r.reconcileSeed(v.Field(i).Type().Name(), seed)
-			if err != nil {
-				completed = false
-				seed.Status.UnfinishedSeeds[v.Field(i).Type().Name()] = err.Error()
-			}
+		if err := step.seed(); err != nil {
+			completed = false
+			seed.Status.UnfinishedSeeds[step.name] = err.Error()
+		} else {
+			delete(seed.Status.UnfinishedSeeds, step.name)
 		}
 	}
 	if !completed {
@@ -110,8 +127,11 @@ func (r *OpenstackSeedReconciler) reconcileSeeds(seed openstackstablesapccv2.Ope
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *OpenstackSeedReconciler) SetupWithManager(mgr ctrl.Manager, opts options.Options) error {
+	r.opts = opts
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&openstackstablesapccv2.OpenstackSeed{}).
+		// status updates must not trigger another reconcile
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
 		WithOptions(controller.Options{MaxConcurrentReconciles: opts.MaxConcurrentReconciles}).
 		Complete(r)
 }
@@ -138,18 +158,55 @@ func (r *OpenstackSeedReconciler) resolveDependencies(ctx context.Context, seed
 	return nil
 }
 
-func (r *OpenstackSeedReconciler) reconcileSeed(name string, seed openstackstablesapccv2.OpenstackSeed) (err error) {
-	switch name {
-	case "domains":
-		err = r.seedDomains(seed.Spec.Domains)
-	case "regions":
-		err = r.seedRegions(seed.Spec.Regions)
-	case "service":
-		err = r.seedServices(seed.Spec.Services)
-	case "role":
-		err = r.seedRoles(seed.Spec.Roles)
+// seedStep is a step of the reconcile. The spec sections are seeded by the steps named after their
+// json names, the resources of the projects by steps of their own.
+type seedStep struct {
+	name string
+	seed func() error
+}
+
+// seedSteps returns the steps of the reconcile of the seed in the order they run in. The steps do not
+// depend on the success of each other, so one failing step does not keep the others from running.
+func (r *OpenstackSeedReconciler) seedSteps(ctx context.Context, seed *openstackstablesapccv2.OpenstackSeed) []seedStep {
+	return []seedStep{
+		{"roles", func() error { return r.seedRoles(seed.Spec.Roles) }},
+		{"regions", func() error { return r.seedRegions(seed.Spec.Regions) }},
+		{"services", func() error { return r.seedServices(seed.Spec.Services) }},
+		{"service_providers", func() error { return r.seedServiceProviders(seed.Spec.ServiceProviders) }},
+		{"flavors", func() error { return r.seedFlavors(seed) }},
+		{"aggregates", func() error { return r.seedAggregates(seed) }},
+		{"share_types", func() error { return r.seedShareTypes(seed) }},
+		{"resource_classes", func() error { return r.seedResourceClasses(ctx, seed.Spec.ResourceClasses) }},
+		{"traits", func() error { return r.seedTraits(seed.Spec.Traits) }},
+		{"network_segment_ranges", func() error { return r.seedNetworkSegmentRanges(seed) }},
+		{"qos_policies", func() error { return r.seedQosPolicies(seed) }},
+		{"domains", func() error { return r.seedDomains(seed.Spec.Domains) }},
+		{"credentials", func() error { return r.seedCredentials(ctx, seed.Namespace, seed.Spec.Domains) }},
+		{"flavor_access", func() error { return r.seedFlavorAccess(ctx, seed) }},
+		{"share_type_access", func() error { return r.seedShareTypeAccess(ctx, seed) }},
+		// per volume type quotas need the volume types, and the resources of the projects might exceed the default quotas
+		{"volume_types", func() error { return r.seedVolumeTypes(ctx, seed) }},
+		{"quotas", func() error { return r.seedQuotas(seed) }},
+		{"subnet_pools", func() error { return r.seedSubnetPools(seed) }},
+		{"project_qos_policies", func() error { return r.seedProjectQosPolicies(seed) }},
+		{"networks", func() error { return r.seedNetworks(ctx, seed) }},
+		{"routers", func() error { return r.seedRouters(seed) }},
+		{"security_groups", func() error { return r.seedSecurityGroups(seed) }},
+		{"ports", func() error { return r.seedPorts(seed) }},
+		{"share_networks", func() error { return r.seedShareNetworks(ctx, seed) }},
+		{"rbac_policies", func() error { return r.seedRBACPolicies(ctx, seed) }},
+		{"qos_specs", func() error { return r.seedQosSpecs(seed.Spec.QosSpecs) }},
+	}
+}
+
+// reconcileSeed runs the seed step of the given name.
+func (r *OpenstackSeedReconciler) reconcileSeed(ctx context.Context, name string, seed *openstackstablesapccv2.OpenstackSeed) error {
+	for _, step := range r.seedSteps(ctx, seed) {
+		if step.name == name {
+			return step.seed()
+		}
 	}
-	return err
+	return nil
 }
 
 func (r *OpenstackSeedReconciler) seedDomains(domains []openstackstablesapccv2.DomainSpec) (err error) {
@@ -163,27 +220,70 @@ func (r *OpenstackSeedReconciler) seedDomains(domains []openstackstablesapccv2.D
 			if _, err := k.SeedDomain(d); err != nil {
 				return err
 			}
+			for _, r := range d.Roles {
+				if r.DomainID == "" && r.Domain == "" {
+					r.Domain = d.Name
+				}
+				if _, err := k.SeedRole(r); err != nil {
+					return err
+				}
+			}
 		}
 	}
 	return
 }
 
-func (r *OpenstackSeedReconciler) seedRegions(regions []openstackstablesapccv2.RegionSpec) (err error) {
+// seedCredentials seeds the credentials of all domain users. The users themselves have to exist already.
+func (r *OpenstackSeedReconciler) seedCredentials(ctx context.Context, namespace string, domains []openstackstablesapccv2.DomainSpec) (err error) {
 	c, err := openstack.NewIdentityClient()
 	if err != nil {
 		return
 	}
 	k := openstack.NewKeystone(c)
-	if len(regions) > 0 {
-		for _, d := range regions {
-			if _, err := k.SeedRegion(d); err != nil {
+	for _, d := range domains {
+		for _, u := range d.Users {
+			if len(u.Credentials) == 0 {
+				continue
+			}
+			userID, err := k.GetUserID(d.Name, u.Name)
+			if err != nil {
 				return err
 			}
+			for _, cred := range u.Credentials {
+				blob, err := r.getSecretValue(ctx, namespace, cred.Blob)
+				if err != nil {
+					return err
+				}
+				if _, err := k.SeedCredential(userID, cred, blob); err != nil {
+					return err
+				}
+			}
 		}
 	}
 	return
 }
 
+// getSecretValue returns the value of a key of a secret in the given namespace.
+func (r *OpenstackSeedReconciler) getSecretValue(ctx context.Context, namespace string, ref openstackstablesapccv2.SecretKeyRef) (string, error) {
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, &secret); err != nil {
+		return "", fmt.Errorf("cannot get secret %s/%s: %w", namespace, ref.Name, err)
+	}
+	v, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %s/%s has no key %s", namespace, ref.Name, ref.Key)
+	}
+	return string(v), nil
+}
+
+func (r *OpenstackSeedReconciler) seedRegions(regions []openstackstablesapccv2.RegionSpec) (err error) {
+	c, err := openstack.NewIdentityClient()
+	if err != nil {
+		return
+	}
+	return openstack.NewKeystone(c).SeedRegions(regions)
+}
+
 func (r *OpenstackSeedReconciler) seedServices(services []openstackstablesapccv2.ServiceSpec) (err error) {
 	c, err := openstack.NewIdentityClient()
 	if err != nil {
@@ -195,6 +295,32 @@ func (r *OpenstackSeedReconciler) seedServices(services []openstackstablesapccv2
 			if _, err := k.SeedService(s); err != nil {
 				return err
 			}
+			if len(s.Endpoints) == 0 {
+				continue
+			}
+			serviceID, err := k.GetServiceID(s.Type, s.Name)
+			if err != nil {
+				return err
+			}
+			for _, e := range s.Endpoints {
+				if _, err := k.SeedEndpoints(e, serviceID); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return
+}
+
+func (r *OpenstackSeedReconciler) seedServiceProviders(sps []openstackstablesapccv2.ServiceProviderSpec) (err error) {
+	c, err := openstack.NewIdentityClient()
+	if err != nil {
+		return
+	}
+	k := openstack.NewKeystone(c)
+	for _, sp := range sps {
+		if _, err := k.SeedServiceProvider(sp); err != nil {
+			return err
 		}
 	}
 	return
@@ -215,3 +341,235 @@ func (r *OpenstackSeedReconciler) seedRoles(roles []openstackstablesapccv2.RoleS
 	}
 	return
 }
+
+func (r *OpenstackSeedReconciler) seedFlavors(seed *openstackstablesapccv2.OpenstackSeed) (err error) {
+	c, err := openstack.NewComputeClient()
+	if err != nil {
+		return
+	}
+	n := openstack.NewNova(c)
+	n.RecreateFlavors = r.opts.RecreateFlavors
+	for _, f := range seed.Spec.Flavors {
+		_, drift, err := n.SeedFlavor(f)
+		r.recordDrift(seed, "FlavorDrift", drift)
+		if err != nil {
+			return err
+		}
+	}
+	return
+}
+
+func (r *OpenstackSeedReconciler) seedAggregates(seed *openstackstablesapccv2.OpenstackSeed) (err error) {
+	c, err := openstack.NewComputeClient()
+	if err != nil {
+		return
+	}
+	n := openstack.NewNova(c)
+	for _, a := range seed.Spec.Aggregates {
+		_, changes, err := n.SeedAggregate(a)
+		for _, msg := range changes {
+			r.Recorder.Event(seed, corev1.EventTypeNormal, "AggregateHosts", msg)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return
+}
+
+func (r *OpenstackSeedReconciler) seedVolumeTypes(ctx context.Context, seed *openstackstablesapccv2.OpenstackSeed) (err error) {
+	if len(seed.Spec.VolumeTypes) == 0 {
+		return
+	}
+	c, err := newCinder()
+	if err != nil {
+		return
+	}
+	for _, vt := range seed.Spec.VolumeTypes {
+		_, drift, err := c.SeedVolumeType(vt)
+		r.recordDrift(seed, "VolumeTypeDrift", drift)
+		if err != nil {
+			return err
+		}
+	}
+	if r.opts.PruneVolumeTypeAccess {
+		err = r.pruneVolumeTypeAccess(ctx, seed, c)
+	}
+	return
+}
+
+// pruneVolumeTypeAccess revokes the access to the private volume types of the seed of all projects
+// which no seed grants it to.
+func (r *OpenstackSeedReconciler) pruneVolumeTypeAccess(ctx context.Context, seed *openstackstablesapccv2.OpenstackSeed, c *openstack.Cinder) (err error) {
+	access := make(map[string][]string)
+	for _, vt := range seed.Spec.VolumeTypes {
+		if vt.IsPublic != nil && !*vt.IsPublic {
+			access[vt.Name] = nil
+		}
+	}
+	if len(access) == 0 {
+		return
+	}
+	var seeds openstackstablesapccv2.OpenstackSeedList
+	if err = r.List(ctx, &seeds); err != nil {
+		return
+	}
+	for _, s := range seeds.Items {
+		own := s.Namespace == seed.Namespace && s.Name == seed.Name
+		for _, vt := range s.Spec.VolumeTypes {
+			if _, ok := access[vt.Name]; !ok {
+				continue
+			}
+			for _, p := range vt.Projects {
+				id, ok, err := seedProjectID(c.Keystone, own, p)
+				if err != nil {
+					return err
+				}
+				if ok {
+					access[vt.Name] = append(access[vt.Name], id)
+				}
+			}
+		}
+	}
+	for name, projectIDs := range access {
+		if err = c.PruneVolumeTypeAccess(name, projectIDs); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (r *OpenstackSeedReconciler) seedQosSpecs(qosSpecs []openstackstablesapccv2.QosSpecSpec) (err error) {
+	if len(qosSpecs) == 0 {
+		return
+	}
+	c, err := newCinder()
+	if err != nil {
+		return
+	}
+	for _, q := range qosSpecs {
+		if _, err := c.SeedQosSpec(q); err != nil {
+			return err
+		}
+	}
+	return
+}
+
+func newKeystone() (*openstack.Keystone, error) {
+	ic, err := openstack.NewIdentityClient()
+	if err != nil {
+		return nil, err
+	}
+	return openstack.NewKeystone(ic), nil
+}
+
+func newNova() (*openstack.Nova, error) {
+	cc, err := openstack.NewComputeClient()
+	if err != nil {
+		return nil, err
+	}
+	return openstack.NewNova(cc), nil
+}
+
+func newManila() (*openstack.Manila, error) {
+	sc, err := openstack.NewSharedFileSystemClient()
+	if err != nil {
+		return nil, err
+	}
+	return openstack.NewManila(sc), nil
+}
+
+func newCinder() (*openstack.Cinder, error) {
+	ic, err := openstack.NewIdentityClient()
+	if err != nil {
+		return nil, err
+	}
+	bc, err := openstack.NewBlockStorageClient()
+	if err != nil {
+		return nil, err
+	}
+	return openstack.NewCinder(bc, openstack.NewKeystone(ic)), nil
+}
+
+func newNeutron() (*openstack.Neutron, error) {
+	ic, err := openstack.NewIdentityClient()
+	if err != nil {
+		return nil, err
+	}
+	nc, err := openstack.NewNetworkClient()
+	if err != nil {
+		return nil, err
+	}
+	return openstack.NewNeutron(nc, openstack.NewKeystone(ic)), nil
+}
+
+// neutronConnector returns a connect function for seedProjects, which sets up neutron.
+func neutronConnector(neutron **openstack.Neutron) func() (*openstack.Keystone, error) {
+	return func() (*openstack.Keystone, error) {
+		n, err := newNeutron()
+		if err != nil {
+			return nil, err
+		}
+		*neutron = n
+		return n.Keystone, nil
+	}
+}
+
+// recordDrift publishes drift in the seed status and as warning events.
+func (r *OpenstackSeedReconciler) recordDrift(seed *openstackstablesapccv2.OpenstackSeed, reason string, drift []openstack.Drift) {
+	for _, d := range drift {
+		msg := d.String()
+		known := false
+		for _, s := range seed.Status.Drift {
+			if s == msg {
+				known = true
+				break
+			}
+		}
+		if !known {
+			seed.Status.Drift = append(seed.Status.Drift, msg)
+		}
+		r.Recorder.Event(seed, corev1.EventTypeWarning, reason, msg)
+	}
+}
+
+// seedProjects calls seedProject with the id of every project of the seed which declares the resources
+// of a step. All projects are validated first, so that an invalid project is not seeded partially.
+// connect sets up the clients the first time a project declares the resources and returns the
+// keystone client which resolves the project ids.
+func seedProjects(seed *openstackstablesapccv2.OpenstackSeed,
+	declares func(p openstackstablesapccv2.ProjectSpec) bool,
+	validate func(d openstackstablesapccv2.DomainSpec, p openstackstablesapccv2.ProjectSpec) error,
+	connect func() (*openstack.Keystone, error),
+	seedProject func(projectID string, d openstackstablesapccv2.DomainSpec, p openstackstablesapccv2.ProjectSpec) error) (err error) {
+	if validate != nil {
+		for _, d := range seed.Spec.Domains {
+			for _, p := range d.Projects {
+				if err = validate(d, p); err != nil {
+					return
+				}
+			}
+		}
+	}
+	var k *openstack.Keystone
+	for _, d := range seed.Spec.Domains {
+		for _, p := range d.Projects {
+			if !declares(p) {
+				continue
+			}
+			if k == nil {
+				if k, err = connect(); err != nil {
+					return
+				}
+			}
+			projectID, err := k.GetProjectID(d.Name, p.Name)
+			if err != nil {
+				return err
+			}
+			if err = seedProject(projectID, d, p); err != nil {
+				return err
+			}
+		}
+	}
+	return
+}
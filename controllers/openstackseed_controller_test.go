@@ -0,0 +1,119 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+)
+
+// Without OS_AUTH_URL every seed function fails as soon as it needs an openstack client,
+// which shows that the section has been reconciled.
+
+func TestReconcileSeed(t *testing.T) {
+	t.Setenv("OS_AUTH_URL", "")
+	r := &OpenstackSeedReconciler{}
+	seed := &openstackstablesapccv2.OpenstackSeed{}
+	for _, name := range []string{"domains", "regions", "services", "service_providers", "roles"} {
+		assert.Error(t, r.reconcileSeed(context.Background(), name, seed), "section %s should be reconciled", name)
+	}
+	assert.NoError(t, r.reconcileSeed(context.Background(), "service", seed), "sections are identified by their json names")
+}
+
+func TestReconcileSeeds(t *testing.T) {
+	t.Setenv("OS_AUTH_URL", "")
+	r := &OpenstackSeedReconciler{}
+	seed := &openstackstablesapccv2.OpenstackSeed{}
+	assert.Error(t, r.reconcileSeeds(context.Background(), seed))
+	for _, name := range []string{"domains", "regions", "services", "service_providers", "roles"} {
+		assert.Contains(t, seed.Status.UnfinishedSeeds, name)
+	}
+
+	seed.Status.UnfinishedSeeds = map[string]string{"regions": "failed before"}
+	assert.Error(t, r.reconcileSeeds(context.Background(), seed))
+	assert.Len(t, seed.Status.UnfinishedSeeds, 1, "only unfinished sections should be retried")
+	assert.NotEqual(t, "failed before", seed.Status.UnfinishedSeeds["regions"])
+}
+
+func TestReconcileSeedsRecordsSteps(t *testing.T) {
+	t.Setenv("OS_AUTH_URL", "")
+	r := &OpenstackSeedReconciler{}
+	seed := &openstackstablesapccv2.OpenstackSeed{}
+	seed.Spec.Domains = []openstackstablesapccv2.DomainSpec{{
+		Name: "monsoon3",
+		Projects: []openstackstablesapccv2.ProjectSpec{{
+			Name:         "admin",
+			Routers:      []openstackstablesapccv2.RouterSpec{{Name: "router"}},
+			ComputeQuota: &openstackstablesapccv2.ComputeQuotaSpec{},
+		}},
+	}}
+	assert.Error(t, r.reconcileSeeds(context.Background(), seed))
+	assert.Contains(t, seed.Status.UnfinishedSeeds, "domains")
+	assert.Contains(t, seed.Status.UnfinishedSeeds, "routers", "a failing step should not hide the later ones")
+	assert.Contains(t, seed.Status.UnfinishedSeeds, "quotas")
+	assert.NotContains(t, seed.Status.UnfinishedSeeds, "volume_types", "quotas should not be recorded as volume types")
+	assert.NotContains(t, seed.Status.UnfinishedSeeds, "networks", "steps without resources should succeed")
+}
+
+func TestReconcileUpdatesStatus(t *testing.T) {
+	t.Setenv("OS_AUTH_URL", "")
+	scheme := runtime.NewScheme()
+	assert.NoError(t, openstackstablesapccv2.AddToScheme(scheme))
+	seed := &openstackstablesapccv2.OpenstackSeed{ObjectMeta: metav1.ObjectMeta{Name: "seed", Namespace: "monsoon3"}}
+	r := &OpenstackSeedReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(seed).Build(),
+		Scheme: scheme,
+	}
+	key := types.NamespacedName{Name: "seed", Namespace: "monsoon3"}
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, result.RequeueAfter)
+
+	var updated openstackstablesapccv2.OpenstackSeed
+	assert.NoError(t, r.Get(context.Background(), key, &updated))
+	assert.Contains(t, updated.Status.UnfinishedSeeds, "domains", "unfinished sections should be persisted")
+	assert.Empty(t, updated.Status.ReconciledResourceVersion)
+}
+
+func TestNewCatalogFindings(t *testing.T) {
+	stale := openstackstablesapccv2.CatalogFinding{Kind: "UndeclaredService", Service: "stale", Message: "service stale (s1) is not declared by any seed"}
+	duplicate := openstackstablesapccv2.CatalogFinding{Kind: "DuplicateEndpoint", Service: "identity", Region: "eu-de-1", Interface: "public", Message: "duplicate"}
+	previous := []openstackstablesapccv2.CatalogFinding{stale}
+	assert.Empty(t, newCatalogFindings(previous, previous), "known findings should not be reported again")
+	assert.Equal(t, []openstackstablesapccv2.CatalogFinding{duplicate}, newCatalogFindings(previous, []openstackstablesapccv2.CatalogFinding{stale, duplicate}))
+	assert.Equal(t, previous, newCatalogFindings(nil, previous))
+}
+
+func TestPrivateFlavorIDs(t *testing.T) {
+	public, private := true, false
+	flavors := []openstackstablesapccv2.FlavorSpec{
+		{Id: "1", Name: "m1.small"},
+		{Id: "2", Name: "m1.large", IsPublic: &public},
+		{Id: "3", Name: "hana.small", IsPublic: &private},
+	}
+	assert.Equal(t, []string{"3"}, privateFlavorIDs(flavors), "the access to private flavors should be seeded even if no project references them")
+}
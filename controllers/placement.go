@@ -0,0 +1,66 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+	"github.com/sapcc/openstack-seeder/openstack"
+)
+
+// seedResourceClasses seeds the custom resource classes of the seed. With --prune-resource-classes
+// custom resource classes which are not declared by any seed are deleted.
+func (r *OpenstackSeedReconciler) seedResourceClasses(ctx context.Context, classes []string) (err error) {
+	if len(classes) == 0 && !r.opts.PruneResourceClasses {
+		return
+	}
+	c, err := openstack.NewPlacementClient()
+	if err != nil {
+		return
+	}
+	p := openstack.NewPlacement(c)
+	if err = p.SeedResourceClasses(classes); err != nil || !r.opts.PruneResourceClasses {
+		return
+	}
+	var seeds openstackstablesapccv2.OpenstackSeedList
+	if err = r.List(ctx, &seeds); err != nil {
+		return
+	}
+	var declared []string
+	for _, s := range seeds.Items {
+		declared = append(declared, s.Spec.ResourceClasses...)
+	}
+	deleted, err := p.PruneResourceClasses(declared)
+	for _, d := range deleted {
+		log.FromContext(ctx).Info("deleted undeclared resource class", "resource_class", d)
+	}
+	return
+}
+
+func (r *OpenstackSeedReconciler) seedTraits(traits []string) (err error) {
+	if len(traits) == 0 {
+		return
+	}
+	c, err := openstack.NewPlacementClient()
+	if err != nil {
+		return
+	}
+	return openstack.NewPlacement(c).SeedTraits(traits)
+}
@@ -0,0 +1,158 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+	"github.com/sapcc/openstack-seeder/openstack"
+)
+
+// quotaSeeder seeds the quota of a service of a project.
+type quotaSeeder func(projectID, project string, p openstackstablesapccv2.ProjectSpec) ([]openstackstablesapccv2.QuotaChange, error)
+
+// quotaService is a service the quotas of the projects are seeded for. Not every region has all of
+// them, so connect only sets up the clients of a service once a project declares a quota of it.
+type quotaService struct {
+	declares func(p openstackstablesapccv2.ProjectSpec) bool
+	connect  func() (quotaSeeder, error)
+}
+
+// quotaServices returns the services the quotas of the projects are seeded for. Per volume type
+// quotas may reference the given volume types.
+func quotaServices(volumeTypes []string) []quotaService {
+	return []quotaService{
+		{
+			declares: func(p openstackstablesapccv2.ProjectSpec) bool { return p.ComputeQuota != nil },
+			connect: func() (quotaSeeder, error) {
+				nova, err := newNova()
+				if err != nil {
+					return nil, err
+				}
+				return func(projectID, project string, p openstackstablesapccv2.ProjectSpec) ([]openstackstablesapccv2.QuotaChange, error) {
+					return nova.SeedComputeQuota(projectID, project, *p.ComputeQuota)
+				}, nil
+			},
+		},
+		{
+			declares: func(p openstackstablesapccv2.ProjectSpec) bool { return p.VolumeQuota != nil },
+			connect: func() (quotaSeeder, error) {
+				cinder, err := newCinder()
+				if err != nil {
+					return nil, err
+				}
+				return func(projectID, project string, p openstackstablesapccv2.ProjectSpec) ([]openstackstablesapccv2.QuotaChange, error) {
+					return cinder.SeedVolumeQuota(projectID, project, *p.VolumeQuota, volumeTypes)
+				}, nil
+			},
+		},
+		{
+			declares: func(p openstackstablesapccv2.ProjectSpec) bool { return p.ShareQuota != nil },
+			connect: func() (quotaSeeder, error) {
+				manila, err := newManila()
+				if err != nil {
+					return nil, err
+				}
+				return func(projectID, project string, p openstackstablesapccv2.ProjectSpec) ([]openstackstablesapccv2.QuotaChange, error) {
+					return manila.SeedShareQuota(projectID, project, *p.ShareQuota)
+				}, nil
+			},
+		},
+		{
+			// the load balancer quotas are seeded through octavia if the catalog has it
+			declares: func(p openstackstablesapccv2.ProjectSpec) bool { return p.NetworkQuota != nil },
+			connect: func() (quotaSeeder, error) {
+				neutron, err := newNeutron()
+				if err != nil {
+					return nil, err
+				}
+				if neutron.Octavia, err = openstack.NewLoadBalancerClient(); err != nil {
+					return nil, err
+				}
+				return func(projectID, project string, p openstackstablesapccv2.ProjectSpec) ([]openstackstablesapccv2.QuotaChange, error) {
+					return neutron.SeedNetworkQuota(projectID, project, *p.NetworkQuota)
+				}, nil
+			},
+		},
+	}
+}
+
+// seedQuotas seeds the compute, volume, share and network quotas of the projects. Per volume type
+// quotas may reference volume types of the seed, so this runs after the volume types have been seeded.
+func (r *OpenstackSeedReconciler) seedQuotas(seed *openstackstablesapccv2.OpenstackSeed) error {
+	var volumeTypes []string
+	for _, vt := range seed.Spec.VolumeTypes {
+		volumeTypes = append(volumeTypes, vt.Name)
+	}
+	services := quotaServices(volumeTypes)
+	seeders := make([]quotaSeeder, len(services))
+	return seedProjects(seed,
+		func(p openstackstablesapccv2.ProjectSpec) bool {
+			for _, s := range services {
+				if s.declares(p) {
+					return true
+				}
+			}
+			return false
+		},
+		nil,
+		newKeystone,
+		func(projectID string, d openstackstablesapccv2.DomainSpec, p openstackstablesapccv2.ProjectSpec) (err error) {
+			for i, s := range services {
+				if !s.declares(p) {
+					continue
+				}
+				if seeders[i] == nil {
+					if seeders[i], err = s.connect(); err != nil {
+						return
+					}
+				}
+				changes, err := seeders[i](projectID, fmt.Sprintf("%s@%s", p.Name, d.Name), p)
+				r.recordQuotaChanges(seed, changes)
+				if err != nil {
+					return err
+				}
+			}
+			return
+		})
+}
+
+// recordQuotaChanges publishes quota changes as events and keeps the last change
+// of every quota in the seed status.
+func (r *OpenstackSeedReconciler) recordQuotaChanges(seed *openstackstablesapccv2.OpenstackSeed, changes []openstackstablesapccv2.QuotaChange) {
+	now := metav1.Now()
+	for _, c := range changes {
+		c.Time = now
+		r.Recorder.Eventf(seed, corev1.EventTypeNormal, "QuotaChanged", "%s quota %s of project %s changed from %d to %d",
+			c.Service, c.Resource, c.Project, c.Old, c.New)
+		replaced := false
+		for i, s := range seed.Status.QuotaChanges {
+			if s.Service == c.Service && s.Project == c.Project && s.Resource == c.Resource {
+				seed.Status.QuotaChanges[i] = c
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			seed.Status.QuotaChanges = append(seed.Status.QuotaChanges, c)
+		}
+	}
+}
@@ -55,6 +55,14 @@ func main() {
 	flag.StringVar(&opts.MetricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&opts.ProbeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.IntVar(&opts.MaxConcurrentReconciles, "max-concurrent-reconciles", 1, "The maximum concurrent reconciles.")
+	flag.StringVar(&opts.CatalogAuditSeed, "catalog-audit-seed", "", "The seed (namespace/name) whose reconcile audits the service catalog against all seeds.")
+	flag.BoolVar(&opts.RecreateFlavors, "recreate-flavors", false, "Delete and recreate flavors whose attributes differ from the seed.")
+	flag.BoolVar(&opts.PruneFlavorAccess, "prune-flavor-access", false, "Revoke the access to private flavors of projects no seed grants it to.")
+	flag.BoolVar(&opts.PruneShareTypeAccess, "prune-share-type-access", false, "Revoke the access to private share types of projects no seed grants it to.")
+	flag.BoolVar(&opts.PruneVolumeTypeAccess, "prune-volume-type-access", false, "Revoke the access to private volume types of projects no seed grants it to.")
+	flag.BoolVar(&opts.PruneResourceClasses, "prune-resource-classes", false, "Delete custom placement resource classes no seed declares.")
+	flag.BoolVar(&opts.PruneSecurityGroupRules, "prune-security-group-rules", false, "Delete the rules of declared security groups which the seed does not declare.")
+	flag.BoolVar(&opts.PruneRBACPolicies, "prune-rbac-policies", false, "Delete the RBAC policies of declared objects which no seed declares.")
 	flag.BoolVar(&opts.EnableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -80,8 +88,9 @@ func main() {
 	}
 
 	if err = (&controllers.OpenstackSeedReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("openstackseed-controller"),
 	}).SetupWithManager(mgr, opts); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "OpenstackSeed")
 		os.Exit(1)
@@ -0,0 +1,458 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/blockstorage/extensions/quotasets"
+	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/qos"
+	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/volumetypes"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+)
+
+type Cinder struct {
+	Client *gophercloud.ServiceClient
+	// Keystone resolves the project names of volume type access lists.
+	Keystone *Keystone
+}
+
+func NewCinder(client *gophercloud.ServiceClient, keystone *Keystone) (c *Cinder) {
+	return &Cinder{
+		Client:   client,
+		Keystone: keystone,
+	}
+}
+
+func NewBlockStorageClient() (client *gophercloud.ServiceClient, err error) {
+	provider, err := newProviderClient()
+	if err != nil {
+		return
+	}
+	client, err = openstack.NewBlockStorageV3(provider, regionEndpointOpts())
+	return
+}
+
+// volumeTypeListOpts lists public and private volume types (admin only).
+type volumeTypeListOpts struct {
+	IsPublic string `q:"is_public"`
+}
+
+func (opts volumeTypeListOpts) ToVolumeTypeListQuery() (string, error) {
+	q, err := gophercloud.BuildQueryString(opts)
+	return q.String(), err
+}
+
+// GetVolumeType returns the volume type with the given name, or nil if it does not exist.
+func (c *Cinder) GetVolumeType(name string) (*volumetypes.VolumeType, error) {
+	p, err := volumetypes.List(c.Client, volumeTypeListOpts{IsPublic: "None"}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	vts, err := volumetypes.ExtractVolumeTypes(p)
+	if err != nil {
+		return nil, err
+	}
+	for i := range vts {
+		if vts[i].Name == name {
+			return &vts[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// SeedVolumeType creates or updates a volume type, its extra specs, its encryption and, for private types,
+// its project access. The encryption of a volume type in use cannot be changed, so it is returned as drift.
+func (c *Cinder) SeedVolumeType(spec openstackstablesapccv2.VolumeTypeSpec) (updated *volumetypes.VolumeType, drift []Drift, err error) {
+	if spec.Encryption != nil {
+		if err = validateVolumeTypeEncryption(spec.Encryption); err != nil {
+			return nil, nil, fmt.Errorf("volume type %s: %w", spec.Name, err)
+		}
+	}
+	updated, err = c.GetVolumeType(spec.Name)
+	if err != nil {
+		return
+	}
+	if updated == nil {
+		updated, err = volumetypes.Create(c.Client, volumetypes.CreateOpts{
+			Name:        spec.Name,
+			Description: spec.Description,
+			IsPublic:    spec.IsPublic,
+			ExtraSpecs:  spec.ExtraSpecs,
+		}).Extract()
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot create volume type %s: %w", spec.Name, err)
+		}
+	} else {
+		var opts volumetypes.UpdateOpts
+		if spec.Description != updated.Description {
+			opts.Description = &spec.Description
+		}
+		if spec.IsPublic != nil && *spec.IsPublic != updated.IsPublic {
+			opts.IsPublic = spec.IsPublic
+		}
+		if opts.Description != nil || opts.IsPublic != nil {
+			if updated, err = volumetypes.Update(c.Client, updated.ID, opts).Extract(); err != nil {
+				return nil, nil, fmt.Errorf("cannot update volume type %s: %w", spec.Name, err)
+			}
+		}
+		if err = c.seedVolumeTypeExtraSpecs(updated.ID, spec.ExtraSpecs); err != nil {
+			return
+		}
+	}
+	if spec.Encryption != nil {
+		if drift, err = c.seedVolumeTypeEncryption(spec.Name, updated.ID, *spec.Encryption); err != nil {
+			return
+		}
+	}
+	if spec.IsPublic != nil && !*spec.IsPublic {
+		err = c.seedVolumeTypeAccess(updated.ID, spec.Projects)
+	}
+	return
+}
+
+func validateVolumeTypeEncryption(spec *openstackstablesapccv2.VolumeTypeEncryptionSpec) error {
+	if spec.Provider == "" {
+		return fmt.Errorf("encryption provider is required")
+	}
+	switch spec.ControlLocation {
+	case "", "front-end", "back-end":
+	default:
+		return fmt.Errorf("invalid encryption control location %s: must be front-end or back-end", spec.ControlLocation)
+	}
+	return nil
+}
+
+// seedVolumeTypeEncryption creates or updates the encryption of a volume type.
+// Cinder refuses to update the encryption of a volume type in use, which is returned as drift.
+func (c *Cinder) seedVolumeTypeEncryption(name, volumeTypeID string, spec openstackstablesapccv2.VolumeTypeEncryptionSpec) (drift []Drift, err error) {
+	desired := VolumeTypeEncryption{
+		Provider:        spec.Provider,
+		Cipher:          spec.Cipher,
+		KeySize:         spec.KeySize,
+		ControlLocation: spec.ControlLocation,
+	}
+	if desired.ControlLocation == "" {
+		desired.ControlLocation = "front-end"
+	}
+	current, err := getVolumeTypeEncryption(c.Client, volumeTypeID)
+	if err != nil {
+		return
+	}
+	if current == nil {
+		if err = createVolumeTypeEncryption(c.Client, volumeTypeID, desired); err != nil {
+			return nil, fmt.Errorf("cannot create encryption of volume type %s: %w", name, err)
+		}
+		return
+	}
+	resource := fmt.Sprintf("volume type %s", name)
+	add := func(field string, desired, actual interface{}) {
+		if desired != actual {
+			drift = append(drift, Drift{Resource: resource, Field: field, Desired: desired, Actual: actual})
+		}
+	}
+	add("encryption provider", desired.Provider, current.Provider)
+	add("encryption cipher", desired.Cipher, current.Cipher)
+	add("encryption key_size", desired.KeySize, current.KeySize)
+	add("encryption control_location", desired.ControlLocation, current.ControlLocation)
+	if len(drift) == 0 {
+		return
+	}
+	if err = updateVolumeTypeEncryption(c.Client, volumeTypeID, current.EncryptionID, desired); err != nil {
+		if _, ok := err.(gophercloud.ErrDefault400); ok {
+			// the volume type is in use
+			return drift, nil
+		}
+		return drift, fmt.Errorf("cannot update encryption of volume type %s: %w", name, err)
+	}
+	return nil, nil
+}
+
+// seedVolumeTypeExtraSpecs adds missing, updates changed and removes undeclared extra specs of a volume type.
+func (c *Cinder) seedVolumeTypeExtraSpecs(volumeTypeID string, specs map[string]string) (err error) {
+	current, err := volumetypes.ListExtraSpecs(c.Client, volumeTypeID).Extract()
+	if err != nil {
+		return
+	}
+	changed := volumetypes.ExtraSpecsOpts{}
+	for k, v := range specs {
+		if cv, ok := current[k]; !ok || cv != v {
+			changed[k] = v
+		}
+	}
+	if len(changed) > 0 {
+		if _, err = volumetypes.CreateExtraSpecs(c.Client, volumeTypeID, changed).Extract(); err != nil {
+			return fmt.Errorf("cannot set extra specs of volume type %s: %w", volumeTypeID, err)
+		}
+	}
+	for k := range current {
+		if _, ok := specs[k]; ok {
+			continue
+		}
+		if err = volumetypes.DeleteExtraSpec(c.Client, volumeTypeID, k).ExtractErr(); err != nil {
+			return fmt.Errorf("cannot delete extra spec %s of volume type %s: %w", k, volumeTypeID, err)
+		}
+	}
+	return
+}
+
+// seedVolumeTypeAccess grants the projects (project_name@domain_name) access to a private volume type.
+// The access of other projects is kept, see PruneVolumeTypeAccess.
+func (c *Cinder) seedVolumeTypeAccess(volumeTypeID string, projects []string) (err error) {
+	granted, err := c.volumeTypeAccess(volumeTypeID)
+	if err != nil {
+		return
+	}
+	for _, p := range projects {
+		id, err := c.Keystone.GetProjectIDByName(p)
+		if err != nil {
+			return err
+		}
+		if granted[id] {
+			continue
+		}
+		if err = volumetypes.AddAccess(c.Client, volumeTypeID, volumetypes.AddAccessOpts{Project: id}).ExtractErr(); err != nil {
+			return fmt.Errorf("cannot grant project %s access to volume type %s: %w", id, volumeTypeID, err)
+		}
+		granted[id] = true
+	}
+	return
+}
+
+// PruneVolumeTypeAccess revokes the access to a private volume type of all projects but the given ones.
+// Public volume types are skipped.
+func (c *Cinder) PruneVolumeTypeAccess(name string, projectIDs []string) (err error) {
+	vt, err := c.GetVolumeType(name)
+	if err != nil {
+		return
+	}
+	if vt == nil {
+		return &NotFoundError{Kind: "volume type", Name: name}
+	}
+	if vt.IsPublic {
+		return
+	}
+	granted, err := c.volumeTypeAccess(vt.ID)
+	if err != nil {
+		return
+	}
+	wanted := make(map[string]bool, len(projectIDs))
+	for _, id := range projectIDs {
+		wanted[id] = true
+	}
+	for id := range granted {
+		if wanted[id] {
+			continue
+		}
+		if err = volumetypes.RemoveAccess(c.Client, vt.ID, volumetypes.RemoveAccessOpts{Project: id}).ExtractErr(); err != nil {
+			return fmt.Errorf("cannot revoke access of project %s to volume type %s: %w", id, name, err)
+		}
+	}
+	return
+}
+
+// volumeTypeAccess returns the ids of the projects which have access to a private volume type.
+func (c *Cinder) volumeTypeAccess(volumeTypeID string) (granted map[string]bool, err error) {
+	p, err := volumetypes.ListAccesses(c.Client, volumeTypeID).AllPages()
+	if err != nil {
+		return
+	}
+	accesses, err := volumetypes.ExtractAccesses(p)
+	if err != nil {
+		return
+	}
+	granted = make(map[string]bool, len(accesses))
+	for _, a := range accesses {
+		granted[a.ProjectID] = true
+	}
+	return
+}
+
+// SeedQosSpec creates or updates a QoS spec and associates it with the declared volume types.
+// Associations with other volume types are removed.
+func (c *Cinder) SeedQosSpec(spec openstackstablesapccv2.QosSpecSpec) (updated *qos.QoS, err error) {
+	switch spec.Consumer {
+	case "", "front-end", "back-end", "both":
+	default:
+		return nil, fmt.Errorf("qos spec %s: invalid consumer %s: must be front-end, back-end or both", spec.Name, spec.Consumer)
+	}
+	consumer := spec.Consumer
+	if consumer == "" {
+		consumer = "back-end"
+	}
+	p, err := qos.List(c.Client, nil).AllPages()
+	if err != nil {
+		return
+	}
+	all, err := qos.ExtractQoS(p)
+	if err != nil {
+		return
+	}
+	for i := range all {
+		if all[i].Name == spec.Name {
+			updated = &all[i]
+			break
+		}
+	}
+	if updated == nil {
+		updated, err = qos.Create(c.Client, qos.CreateOpts{
+			Name:     spec.Name,
+			Consumer: qos.QoSConsumer(consumer),
+			Specs:    spec.Specs,
+		}).Extract()
+		if err != nil {
+			return nil, fmt.Errorf("cannot create qos spec %s: %w", spec.Name, err)
+		}
+	} else {
+		changed := make(map[string]string)
+		if updated.Consumer != consumer {
+			changed["consumer"] = consumer
+		}
+		for k, v := range spec.Specs {
+			if cv, ok := updated.Specs[k]; !ok || cv != v {
+				changed[k] = v
+			}
+		}
+		if len(changed) > 0 {
+			if err = setQosSpecKeys(c.Client, updated.ID, changed); err != nil {
+				return nil, fmt.Errorf("cannot update qos spec %s: %w", spec.Name, err)
+			}
+		}
+		var removed []string
+		for k := range updated.Specs {
+			if _, ok := spec.Specs[k]; !ok {
+				removed = append(removed, k)
+			}
+		}
+		if len(removed) > 0 {
+			sort.Strings(removed)
+			if err = deleteQosSpecKeys(c.Client, updated.ID, removed); err != nil {
+				return nil, fmt.Errorf("cannot delete keys of qos spec %s: %w", spec.Name, err)
+			}
+		}
+	}
+	return updated, c.seedQosAssociations(spec, updated.ID)
+}
+
+func (c *Cinder) seedQosAssociations(spec openstackstablesapccv2.QosSpecSpec, qosID string) (err error) {
+	associations, err := listQosAssociations(c.Client, qosID)
+	if err != nil {
+		return
+	}
+	associated := make(map[string]bool, len(associations))
+	for _, a := range associations {
+		associated[a.ID] = true
+	}
+	wanted := make(map[string]bool, len(spec.VolumeTypes))
+	for _, name := range spec.VolumeTypes {
+		vt, err := c.GetVolumeType(name)
+		if err != nil {
+			return err
+		}
+		if vt == nil {
+			return fmt.Errorf("qos spec %s: could not find volume type: %s", spec.Name, name)
+		}
+		wanted[vt.ID] = true
+		if associated[vt.ID] {
+			continue
+		}
+		if vt.QosSpecID != "" {
+			// a volume type can only be associated with a single qos spec
+			if err = associateQosSpec(c.Client, vt.QosSpecID, "disassociate", vt.ID); err != nil {
+				return fmt.Errorf("cannot disassociate volume type %s from its qos spec: %w", name, err)
+			}
+		}
+		if err = associateQosSpec(c.Client, qosID, "associate", vt.ID); err != nil {
+			return fmt.Errorf("cannot associate qos spec %s with volume type %s: %w", spec.Name, name, err)
+		}
+	}
+	for _, a := range associations {
+		if a.AssociationType != "volume_type" || wanted[a.ID] {
+			continue
+		}
+		if err = associateQosSpec(c.Client, qosID, "disassociate", a.ID); err != nil {
+			return fmt.Errorf("cannot disassociate qos spec %s from volume type %s: %w", spec.Name, a.Name, err)
+		}
+	}
+	return
+}
+
+// volumeTypeQuotaResources are the quota resources which can be set per volume type (e.g. gigabytes_<type>).
+var volumeTypeQuotaResources = []string{"volumes_", "gigabytes_", "snapshots_"}
+
+// SeedVolumeQuota updates the keys of the project's block storage quota which differ from the spec.
+// Per volume type keys have to reference one of the seeded volume types or a volume type known to cinder.
+func (c *Cinder) SeedVolumeQuota(projectID, project string, spec openstackstablesapccv2.VolumeQuotaSpec, volumeTypes []string) (changes []openstackstablesapccv2.QuotaChange, err error) {
+	var desired map[string]interface{}
+	perType := spec.VolumeTypes
+	spec.VolumeTypes = nil
+	b, _ := json.Marshal(spec)
+	json.Unmarshal(b, &desired)
+	for k, v := range perType {
+		if err = c.validateVolumeTypeQuota(k, volumeTypes); err != nil {
+			return nil, fmt.Errorf("volume quota of project %s: %w", project, err)
+		}
+		desired[k] = v
+	}
+
+	var current struct {
+		QuotaSet map[string]interface{} `json:"quota_set"`
+	}
+	if err = quotasets.Get(c.Client, projectID).ExtractInto(&current); err != nil {
+		return
+	}
+	opts, changes := diffQuota("volume", project, desired, current.QuotaSet)
+	if len(opts) == 0 {
+		return
+	}
+	if _, err = quotasets.Update(c.Client, projectID, opts).Extract(); err != nil {
+		return nil, fmt.Errorf("cannot update volume quota of project %s: %w", project, err)
+	}
+	return
+}
+
+func (c *Cinder) validateVolumeTypeQuota(key string, volumeTypes []string) error {
+	var name string
+	for _, r := range volumeTypeQuotaResources {
+		if strings.HasPrefix(key, r) {
+			name = strings.TrimPrefix(key, r)
+			break
+		}
+	}
+	if name == "" {
+		return fmt.Errorf("invalid volume type quota %s: must be volumes_<type>, gigabytes_<type> or snapshots_<type>", key)
+	}
+	for _, vt := range volumeTypes {
+		if vt == name {
+			return nil
+		}
+	}
+	vt, err := c.GetVolumeType(name)
+	if err != nil {
+		return err
+	}
+	if vt == nil {
+		return fmt.Errorf("quota %s references unknown volume type %s", key, name)
+	}
+	return nil
+}
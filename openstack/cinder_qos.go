@@ -0,0 +1,109 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"net/url"
+
+	"github.com/gophercloud/gophercloud"
+)
+
+// gophercloud only supports creating, listing and deleting QoS specs and does not support
+// volume type encryption (yet), so the remaining requests the seeder needs are implemented here.
+
+// VolumeTypeEncryption is the encryption of a volume type
+// (see https://docs.openstack.org/api-ref/block-storage/v3/#volume-type-encryption).
+type VolumeTypeEncryption struct {
+	EncryptionID    string `json:"encryption_id,omitempty"`
+	Provider        string `json:"provider"`
+	Cipher          string `json:"cipher,omitempty"`
+	KeySize         int    `json:"key_size,omitempty"`
+	ControlLocation string `json:"control_location"`
+}
+
+type qosAssociation struct {
+	AssociationType string `json:"association_type"`
+	Name            string `json:"name"`
+	ID              string `json:"id"`
+}
+
+func qosSpecURL(c *gophercloud.ServiceClient, parts ...string) string {
+	return c.ServiceURL(append([]string{"qos-specs"}, parts...)...)
+}
+
+// setQosSpecKeys sets (and adds) the given keys of a QoS spec. The consumer is set like a key.
+func setQosSpecKeys(c *gophercloud.ServiceClient, id string, keys map[string]string) error {
+	_, err := c.Put(qosSpecURL(c, id), map[string]interface{}{"qos_specs": keys}, nil, &gophercloud.RequestOpts{
+		OkCodes: []int{200},
+	})
+	return err
+}
+
+func deleteQosSpecKeys(c *gophercloud.ServiceClient, id string, keys []string) error {
+	_, err := c.Put(qosSpecURL(c, id, "delete_keys"), map[string]interface{}{"keys": keys}, nil, &gophercloud.RequestOpts{
+		OkCodes: []int{202},
+	})
+	return err
+}
+
+func listQosAssociations(c *gophercloud.ServiceClient, id string) ([]qosAssociation, error) {
+	var r struct {
+		Associations []qosAssociation `json:"qos_associations"`
+	}
+	_, err := c.Get(qosSpecURL(c, id, "associations"), &r, nil)
+	return r.Associations, err
+}
+
+// associateQosSpec associates (action "associate") or disassociates (action "disassociate")
+// a QoS spec with a volume type.
+func associateQosSpec(c *gophercloud.ServiceClient, id, action, volumeTypeID string) error {
+	u := qosSpecURL(c, id, action) + "?vol_type_id=" + url.QueryEscape(volumeTypeID)
+	_, err := c.Get(u, nil, &gophercloud.RequestOpts{
+		OkCodes: []int{202},
+	})
+	return err
+}
+
+func volumeTypeEncryptionURL(c *gophercloud.ServiceClient, volumeTypeID string, parts ...string) string {
+	return c.ServiceURL(append([]string{"types", volumeTypeID, "encryption"}, parts...)...)
+}
+
+// getVolumeTypeEncryption returns the encryption of a volume type, or nil if the volume type is not encrypted.
+func getVolumeTypeEncryption(c *gophercloud.ServiceClient, volumeTypeID string) (*VolumeTypeEncryption, error) {
+	var r VolumeTypeEncryption
+	if _, err := c.Get(volumeTypeEncryptionURL(c, volumeTypeID), &r, nil); err != nil {
+		return nil, err
+	}
+	if r.EncryptionID == "" {
+		return nil, nil
+	}
+	return &r, nil
+}
+
+func createVolumeTypeEncryption(c *gophercloud.ServiceClient, volumeTypeID string, e VolumeTypeEncryption) error {
+	_, err := c.Post(volumeTypeEncryptionURL(c, volumeTypeID), map[string]interface{}{"encryption": e}, nil, &gophercloud.RequestOpts{
+		OkCodes: []int{200},
+	})
+	return err
+}
+
+func updateVolumeTypeEncryption(c *gophercloud.ServiceClient, volumeTypeID, encryptionID string, e VolumeTypeEncryption) error {
+	_, err := c.Put(volumeTypeEncryptionURL(c, volumeTypeID, encryptionID), map[string]interface{}{"encryption": e}, nil, &gophercloud.RequestOpts{
+		OkCodes: []int{200},
+	})
+	return err
+}
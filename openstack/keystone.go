@@ -20,12 +20,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/gophercloud/gophercloud"
 	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/credentials"
 	"github.com/gophercloud/gophercloud/openstack/identity/v3/domains"
 	"github.com/gophercloud/gophercloud/openstack/identity/v3/endpoints"
 	"github.com/gophercloud/gophercloud/openstack/identity/v3/regions"
@@ -50,21 +50,21 @@ func NewKeystone(client *gophercloud.ServiceClient) (k *Keystone) {
 }
 
 func NewIdentityClient() (client *gophercloud.ServiceClient, err error) {
-	opts, err := openstack.AuthOptionsFromEnv()
+	provider, err := newProviderClient()
 	if err != nil {
 		return
 	}
-	provider, err := openstack.AuthenticatedClient(opts)
-	if err != nil {
-		return
-	}
-	client, err = openstack.NewIdentityV3(provider, gophercloud.EndpointOpts{
-		Region: os.Getenv("OS_REGION_NAME"),
-	})
+	client, err = openstack.NewIdentityV3(provider, regionEndpointOpts())
 	return
 }
 
 func (k *Keystone) SeedRole(spec openstackstablesapccv2.RoleSpec) (updated *roles.Role, err error) {
+	if spec.Domain != "" {
+		if spec.DomainID, err = k.GetDomainID(spec.Domain); err != nil {
+			return
+		}
+		spec.Domain = ""
+	}
 	p, err := roles.List(k.Client, roles.ListOpts{
 		Name:     spec.Name,
 		DomainID: spec.DomainID,
@@ -100,36 +100,56 @@ func (k *Keystone) SeedRole(spec openstackstablesapccv2.RoleSpec) (updated *role
 	return
 }
 
+// SeedRegions seeds the given regions parents first, so that a region is never
+// created before the parent it references.
+func (k *Keystone) SeedRegions(specs []openstackstablesapccv2.RegionSpec) (err error) {
+	sorted, err := sortRegions(specs)
+	if err != nil {
+		return
+	}
+	for _, spec := range sorted {
+		if _, err = k.SeedRegion(spec); err != nil {
+			return fmt.Errorf("region %s: %w", spec.ID, err)
+		}
+	}
+	return
+}
+
 func (k *Keystone) SeedRegion(spec openstackstablesapccv2.RegionSpec) (updated *regions.Region, err error) {
 	if spec.ID == "" {
 		//TODO: warning log
 		return
 	}
-	r := regions.Get(k.Client, spec.ID)
-	region, err := r.Extract()
+	region, err := regions.Get(k.Client, spec.ID).Extract()
 	if err != nil {
-		return
-	}
-	d, _ := json.Marshal(spec)
-	specRegion := regions.Region{}
-	specRegion.UnmarshalJSON(d)
-	if region == nil {
-		opts := regions.CreateOpts{}
-		b, err := json.Marshal(specRegion)
-		if err != nil {
-			return updated, err
+		if _, ok := err.(gophercloud.ErrDefault404); !ok {
+			return
 		}
-		json.Unmarshal(b, &opts)
-		opts.Extra = specRegion.Extra
-		return regions.Create(k.Client, opts).Extract()
+		return regions.Create(k.Client, regions.CreateOpts{
+			ID:             spec.ID,
+			Description:    spec.Description,
+			ParentRegionID: spec.ParentRegionID,
+		}).Extract()
 	}
-	if !isEqual(specRegion, region) {
-		opts := regions.UpdateOpts{}
-		b, _ := json.Marshal(specRegion)
-		json.Unmarshal(b, &opts)
-		return regions.Update(k.Client, region.ID, opts).Extract()
+	if region.Description == spec.Description && region.ParentRegionID == spec.ParentRegionID {
+		return
 	}
-	return
+	opts := regionUpdateOpts{Description: spec.Description}
+	if spec.ParentRegionID != "" {
+		opts.ParentRegionID = &spec.ParentRegionID
+	}
+	return regions.Update(k.Client, region.ID, opts).Extract()
+}
+
+// regionUpdateOpts always sends the parent region, so that a parent can also be
+// removed again (regions.UpdateOpts omits an empty parent_region_id).
+type regionUpdateOpts struct {
+	Description    string  `json:"description"`
+	ParentRegionID *string `json:"parent_region_id"`
+}
+
+func (opts regionUpdateOpts) ToRegionUpdateMap() (map[string]interface{}, error) {
+	return gophercloud.BuildRequestBody(opts, "region")
 }
 
 func (k *Keystone) SeedService(spec openstackstablesapccv2.ServiceSpec) (updated *services.Service, err error) {
@@ -158,6 +178,35 @@ func (k *Keystone) SeedService(spec openstackstablesapccv2.ServiceSpec) (updated
 	return
 }
 
+// SeedServiceProvider creates or updates a keystone-to-keystone federation service provider.
+func (k *Keystone) SeedServiceProvider(spec openstackstablesapccv2.ServiceProviderSpec) (updated *ServiceProvider, err error) {
+	for _, u := range []string{spec.AuthURL, spec.SPURL} {
+		if _, err = url.ParseRequestURI(u); err != nil {
+			return
+		}
+	}
+	opts := serviceProviderOpts{
+		Description:      spec.Description,
+		AuthURL:          spec.AuthURL,
+		SPURL:            spec.SPURL,
+		RelayStatePrefix: spec.RelayStatePrefix,
+		Enabled:          spec.Enabled,
+	}
+	sp, err := getServiceProvider(k.Client, spec.ID)
+	if err != nil {
+		if _, ok := err.(gophercloud.ErrDefault404); !ok {
+			return
+		}
+		return createServiceProvider(k.Client, spec.ID, opts)
+	}
+	if sp.Description == spec.Description && sp.AuthURL == spec.AuthURL && sp.SPURL == spec.SPURL &&
+		(spec.RelayStatePrefix == "" || sp.RelayStatePrefix == spec.RelayStatePrefix) &&
+		(spec.Enabled == nil || sp.Enabled == *spec.Enabled) {
+		return
+	}
+	return updateServiceProvider(k.Client, spec.ID, opts)
+}
+
 func (k *Keystone) SeedDomain(spec openstackstablesapccv2.DomainSpec) (updated *domains.Domain, err error) {
 	p, err := domains.List(k.Client, domains.ListOpts{Name: spec.Name}).AllPages()
 	if err != nil {
@@ -195,8 +244,9 @@ func (k *Keystone) SeedEndpoints(spec openstackstablesapccv2.EndpointSpec, servi
 	}
 
 	p, err := endpoints.List(k.Client, endpoints.ListOpts{
-		ServiceID: serviceID,
-		RegionID:  spec.Region,
+		Availability: gophercloud.Availability(spec.Interface),
+		ServiceID:    serviceID,
+		RegionID:     spec.Region,
 	}).AllPages()
 	if err != nil {
 		return
@@ -205,28 +255,44 @@ func (k *Keystone) SeedEndpoints(spec openstackstablesapccv2.EndpointSpec, servi
 	if err != nil {
 		return
 	}
+	opts := endpointOpts{
+		Availability: gophercloud.Availability(spec.Interface),
+		Region:       spec.Region,
+		URL:          spec.URL,
+		ServiceID:    serviceID,
+		Enabled:      spec.Enabled,
+	}
 	if len(e) == 0 {
-		opts := endpoints.CreateOpts{}
-		b, err := json.Marshal(spec)
-		if err != nil {
-			return updated, err
-		}
-		json.Unmarshal(b, &opts)
 		return endpoints.Create(k.Client, opts).Extract()
 	}
 	endpoint := e[0]
-	d, _ := json.Marshal(spec)
-	specEndpoint := endpoints.Endpoint{}
-	json.Unmarshal(d, &specEndpoint)
-	if !isEqual(specEndpoint, endpoint) {
-		upd := endpoints.UpdateOpts{}
-		json.Unmarshal(d, &upd)
-		return endpoints.Update(k.Client, endpoint.ID, upd).Extract()
+	if endpoint.URL != spec.URL || endpoint.Enabled != spec.Enabled {
+		return endpoints.Update(k.Client, endpoint.ID, opts).Extract()
 	}
 	return
 }
 
+// endpointOpts is used instead of endpoints.CreateOpts and endpoints.UpdateOpts, which require
+// an endpoint name and cannot change the enabled flag.
+type endpointOpts struct {
+	Availability gophercloud.Availability `json:"interface"`
+	Region       string                   `json:"region_id,omitempty"`
+	URL          string                   `json:"url"`
+	ServiceID    string                   `json:"service_id"`
+	Enabled      bool                     `json:"enabled"`
+}
+
+func (opts endpointOpts) ToEndpointCreateMap() (map[string]interface{}, error) {
+	return gophercloud.BuildRequestBody(opts, "endpoint")
+}
+
+func (opts endpointOpts) ToEndpointUpdateMap() (map[string]interface{}, error) {
+	return gophercloud.BuildRequestBody(opts, "endpoint")
+}
+
 func (k *Keystone) SeedUser(spec openstackstablesapccv2.UserSpec) (updated *users.User, err error) {
+	// credentials are seeded separately (see SeedCredential)
+	spec.Credentials = nil
 	p, err := users.List(k.Client, users.ListOpts{
 		DomainID: "",
 		Name:     spec.Name,
@@ -294,3 +360,78 @@ func (k *Keystone) SeedRoleAssignment(spec openstackstablesapccv2.RoleAssignment
 	}
 	return roles.Assign(k.Client, roleID, assignOpts).ExtractErr()
 }
+
+// SeedCredential creates or updates a credential of the given user. A user has at most one
+// credential per type and project, except for ec2 credentials, which are matched by their access key. The blob usually contains a secret, so it is only ever
+// compared and must not end up in logs or errors.
+func (k *Keystone) SeedCredential(userID string, spec openstackstablesapccv2.CredentialSpec, blob string) (updated *credentials.Credential, err error) {
+	var projectID string
+	if spec.Project != "" {
+		if projectID, err = k.GetProjectIDByName(spec.Project); err != nil {
+			return
+		}
+	}
+	p, err := credentials.List(k.Client, credentials.ListOpts{
+		UserID: userID,
+		Type:   spec.Type,
+	}).AllPages()
+	if err != nil {
+		return
+	}
+	cs, err := credentials.ExtractCredentials(p)
+	if err != nil {
+		return
+	}
+	accessKey, err := ec2AccessKey(spec.Type, blob)
+	if err != nil {
+		return nil, fmt.Errorf("ec2 credential of user %s: %w", userID, err)
+	}
+	for _, c := range cs {
+		if c.ProjectID != projectID {
+			continue
+		}
+		if spec.Type == "ec2" {
+			// a user may have several ec2 credentials of their own, which are told apart by their access key
+			if key, _ := ec2AccessKey(c.Type, c.Blob); key != accessKey {
+				continue
+			}
+		}
+		if c.Blob == blob {
+			return
+		}
+		updated, err = credentials.Update(k.Client, c.ID, credentials.UpdateOpts{Blob: blob}).Extract()
+		return updated, credentialError("update", spec.Type, userID, err)
+	}
+	updated, err = credentials.Create(k.Client, credentials.CreateOpts{
+		Blob:      blob,
+		ProjectID: projectID,
+		Type:      spec.Type,
+		UserID:    userID,
+	}).Extract()
+	return updated, credentialError("create", spec.Type, userID, err)
+}
+
+// ec2AccessKey returns the access key of an ec2 credential blob.
+func ec2AccessKey(credentialType, blob string) (string, error) {
+	if credentialType != "ec2" {
+		return "", nil
+	}
+	var ec2 struct {
+		Access string `json:"access"`
+	}
+	if json.Unmarshal([]byte(blob), &ec2) != nil || ec2.Access == "" {
+		return "", fmt.Errorf("blob has no access key")
+	}
+	return ec2.Access, nil
+}
+
+// credentialError drops the response body of a failed request, as keystone might echo the blob.
+func credentialError(action, credentialType, userID string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if e, ok := err.(gophercloud.StatusCodeError); ok {
+		return fmt.Errorf("could not %s %s credential of user %s: got status %d", action, credentialType, userID, e.GetStatusCode())
+	}
+	return fmt.Errorf("could not %s %s credential of user %s", action, credentialType, userID)
+}
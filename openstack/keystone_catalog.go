@@ -0,0 +1,132 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/endpoints"
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/services"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+)
+
+// Kinds of catalog findings
+const (
+	UndeclaredService  = "UndeclaredService"
+	UndeclaredEndpoint = "UndeclaredEndpoint"
+	DuplicateEndpoint  = "DuplicateEndpoint"
+)
+
+// CatalogFindingKinds lists all kinds of catalog findings.
+var CatalogFindingKinds = []string{UndeclaredService, UndeclaredEndpoint, DuplicateEndpoint}
+
+type endpointKey struct {
+	service, region, iface string
+}
+
+// AuditCatalog compares all services and endpoints in the catalog against the declared services
+// (usually the services of all seeds). It reports services and endpoints no seed declares, endpoints
+// whose URL differs from the declared one and multiple endpoints per service, region and interface.
+func (k *Keystone) AuditCatalog(declared []openstackstablesapccv2.ServiceSpec) (findings []openstackstablesapccv2.CatalogFinding, err error) {
+	p, err := services.List(k.Client, services.ListOpts{}).AllPages()
+	if err != nil {
+		return
+	}
+	svcs, err := services.ExtractServices(p)
+	if err != nil {
+		return
+	}
+	p, err = endpoints.List(k.Client, endpoints.ListOpts{}).AllPages()
+	if err != nil {
+		return
+	}
+	eps, err := endpoints.ExtractEndpoints(p)
+	if err != nil {
+		return
+	}
+
+	declaredServices := make(map[string]bool)
+	declaredEndpoints := make(map[endpointKey]string)
+	for _, s := range declared {
+		name := s.Type + "/" + s.Name
+		declaredServices[name] = true
+		for _, e := range s.Endpoints {
+			declaredEndpoints[endpointKey{name, e.Region, e.Interface}] = e.URL
+		}
+	}
+
+	serviceNames := make(map[string]string, len(svcs))
+	for _, s := range svcs {
+		n, _ := s.Extra["name"].(string)
+		name := s.Type + "/" + n
+		serviceNames[s.ID] = name
+		if !declaredServices[name] {
+			findings = append(findings, openstackstablesapccv2.CatalogFinding{
+				Kind:    UndeclaredService,
+				Service: name,
+				Message: fmt.Sprintf("service %s (%s) is not declared by any seed", name, s.ID),
+			})
+		}
+	}
+
+	seen := make(map[endpointKey][]string)
+	for _, e := range eps {
+		key := endpointKey{serviceNames[e.ServiceID], e.Region, string(e.Availability)}
+		seen[key] = append(seen[key], e.ID)
+		url, ok := declaredEndpoints[key]
+		if ok && url == e.URL {
+			continue
+		}
+		f := openstackstablesapccv2.CatalogFinding{
+			Kind:      UndeclaredEndpoint,
+			Service:   key.service,
+			Region:    key.region,
+			Interface: key.iface,
+			URL:       e.URL,
+			Message:   fmt.Sprintf("%s endpoint %s of service %s in region %s is not declared by any seed", key.iface, e.ID, key.service, key.region),
+		}
+		if ok {
+			f.Message = fmt.Sprintf("%s endpoint %s of service %s in region %s has url %s instead of %s", key.iface, e.ID, key.service, key.region, e.URL, url)
+		}
+		findings = append(findings, f)
+	}
+	for key, ids := range seen {
+		if len(ids) < 2 {
+			continue
+		}
+		sort.Strings(ids)
+		findings = append(findings, openstackstablesapccv2.CatalogFinding{
+			Kind:      DuplicateEndpoint,
+			Service:   key.service,
+			Region:    key.region,
+			Interface: key.iface,
+			Message:   fmt.Sprintf("service %s has %d %s endpoints in region %s: %v", key.service, len(ids), key.iface, key.region, ids),
+		})
+	}
+
+	// keep the order stable, so that an unchanged catalog does not change the seed status
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Message < b.Message
+	})
+	return
+}
@@ -0,0 +1,73 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"github.com/gophercloud/gophercloud"
+)
+
+// gophercloud does not support the OS-FEDERATION service provider API (yet),
+// so the few requests the seeder needs are implemented here.
+
+// ServiceProvider is a keystone-to-keystone federation service provider
+// (see https://docs.openstack.org/api-ref/identity/v3-ext/#service-providers).
+type ServiceProvider struct {
+	ID               string `json:"id"`
+	Description      string `json:"description"`
+	AuthURL          string `json:"auth_url"`
+	SPURL            string `json:"sp_url"`
+	RelayStatePrefix string `json:"relay_state_prefix"`
+	Enabled          bool   `json:"enabled"`
+}
+
+type serviceProviderOpts struct {
+	Description      string `json:"description"`
+	AuthURL          string `json:"auth_url"`
+	SPURL            string `json:"sp_url"`
+	RelayStatePrefix string `json:"relay_state_prefix,omitempty"`
+	Enabled          *bool  `json:"enabled,omitempty"`
+}
+
+type serviceProviderResult struct {
+	ServiceProvider *ServiceProvider `json:"service_provider"`
+}
+
+func serviceProviderURL(c *gophercloud.ServiceClient, id string) string {
+	return c.ServiceURL("OS-FEDERATION", "service_providers", id)
+}
+
+func getServiceProvider(c *gophercloud.ServiceClient, id string) (*ServiceProvider, error) {
+	var r serviceProviderResult
+	_, err := c.Get(serviceProviderURL(c, id), &r, nil)
+	return r.ServiceProvider, err
+}
+
+func createServiceProvider(c *gophercloud.ServiceClient, id string, opts serviceProviderOpts) (*ServiceProvider, error) {
+	var r serviceProviderResult
+	_, err := c.Put(serviceProviderURL(c, id), map[string]interface{}{"service_provider": opts}, &r, &gophercloud.RequestOpts{
+		OkCodes: []int{201},
+	})
+	return r.ServiceProvider, err
+}
+
+func updateServiceProvider(c *gophercloud.ServiceClient, id string, opts serviceProviderOpts) (*ServiceProvider, error) {
+	var r serviceProviderResult
+	_, err := c.Patch(serviceProviderURL(c, id), map[string]interface{}{"service_provider": opts}, &r, &gophercloud.RequestOpts{
+		OkCodes: []int{200},
+	})
+	return r.ServiceProvider, err
+}
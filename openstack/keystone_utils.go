@@ -18,12 +18,17 @@ package openstack
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/gophercloud/gophercloud/openstack/identity/v3/domains"
 	"github.com/gophercloud/gophercloud/openstack/identity/v3/groups"
 	"github.com/gophercloud/gophercloud/openstack/identity/v3/projects"
 	"github.com/gophercloud/gophercloud/openstack/identity/v3/roles"
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/services"
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/tokens"
 	"github.com/gophercloud/gophercloud/openstack/identity/v3/users"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
 )
 
 func (k *Keystone) GetProjectID(domain, name string) (id string, err error) {
@@ -46,12 +51,37 @@ func (k *Keystone) GetProjectID(domain, name string) (id string, err error) {
 		return
 	}
 	if len(r) != 1 {
-		return id, fmt.Errorf("could not find project: %s", name)
+		return id, &NotFoundError{Kind: "project", Name: name}
 	}
 	k.cache.Add("project", fmt.Sprintf("%s.%s", domain, name), r[0].ID, 0)
 	return r[0].ID, err
 }
 
+// GetTokenProjectID returns the id of the project the token of the seeder is scoped to.
+func (k *Keystone) GetTokenProjectID() (id string, err error) {
+	if id, ok := k.cache.Get("token", "project"); ok {
+		return id, nil
+	}
+	p, err := tokens.Get(k.Client, k.Client.TokenID).ExtractProject()
+	if err != nil {
+		return
+	}
+	if p == nil {
+		return id, fmt.Errorf("token is not scoped to a project")
+	}
+	k.cache.Add("token", "project", p.ID, 0)
+	return p.ID, nil
+}
+
+// GetProjectIDByName returns the id of a project referenced as project_name@domain_name.
+func (k *Keystone) GetProjectIDByName(name string) (id string, err error) {
+	n := strings.Split(name, "@")
+	if len(n) != 2 {
+		return id, fmt.Errorf("project name wrong format: project_name@domain_name")
+	}
+	return k.GetProjectID(n[1], n[0])
+}
+
 func (k *Keystone) GetDomainID(name string) (id string, err error) {
 	if id, ok := k.cache.Get("domain", name); ok {
 		return id, nil
@@ -65,7 +95,7 @@ func (k *Keystone) GetDomainID(name string) (id string, err error) {
 		return
 	}
 	if len(d) != 1 {
-		return id, fmt.Errorf("could not find domain: %s", name)
+		return id, &NotFoundError{Kind: "domain", Name: name}
 	}
 	k.cache.Add("domain", name, d[0].ID, 0)
 	return d[0].ID, err
@@ -123,11 +153,23 @@ func (k *Keystone) GetGroupID(domain, name string) (id string, err error) {
 	return u[0].ID, err
 }
 
+// GetRoleID returns the id of a global role, or of a domain-specific role if the
+// name is given as role@domain.
 func (k *Keystone) GetRoleID(name string) (id string, err error) {
 	if id, ok := k.cache.Get("role", name); ok {
 		return id, nil
 	}
-	p, err := roles.List(k.Client, roles.ListOpts{Name: name}).AllPages()
+	opts := roles.ListOpts{Name: name}
+	if n := strings.Split(name, "@"); len(n) == 2 {
+		opts.Name = n[0]
+		opts.DomainID, err = k.GetDomainID(n[1])
+		if err != nil {
+			return
+		}
+	} else if len(n) > 2 {
+		return id, fmt.Errorf("role name wrong format: role@domain")
+	}
+	p, err := roles.List(k.Client, opts).AllPages()
 	if err != nil {
 		return
 	}
@@ -136,8 +178,79 @@ func (k *Keystone) GetRoleID(name string) (id string, err error) {
 		return
 	}
 	if len(r) != 1 {
-		return id, fmt.Errorf("could not find user: %s", name)
+		return id, fmt.Errorf("could not find role: %s", name)
 	}
 	k.cache.Add("role", name, r[0].ID, 0)
 	return r[0].ID, err
 }
+
+func (k *Keystone) GetServiceID(serviceType, name string) (id string, err error) {
+	if id, ok := k.cache.Get("service", fmt.Sprintf("%s.%s", serviceType, name)); ok {
+		return id, nil
+	}
+	p, err := services.List(k.Client, services.ListOpts{
+		ServiceType: serviceType,
+		Name:        name,
+	}).AllPages()
+	if err != nil {
+		return
+	}
+	s, err := services.ExtractServices(p)
+	if err != nil {
+		return
+	}
+	if len(s) != 1 {
+		return id, fmt.Errorf("could not find service: %s/%s", serviceType, name)
+	}
+	k.cache.Add("service", fmt.Sprintf("%s.%s", serviceType, name), s[0].ID, 0)
+	return s[0].ID, err
+}
+
+// sortRegions orders the regions so that every parent comes before its children.
+// Parents which are not part of specs are expected to exist in keystone already.
+func sortRegions(specs []openstackstablesapccv2.RegionSpec) (sorted []openstackstablesapccv2.RegionSpec, err error) {
+	byID := make(map[string]openstackstablesapccv2.RegionSpec, len(specs))
+	for _, s := range specs {
+		if _, ok := byID[s.ID]; ok {
+			return nil, fmt.Errorf("duplicate region: %s", s.ID)
+		}
+		byID[s.ID] = s
+	}
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int, len(specs))
+	var path []string
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visited:
+			return nil
+		case visiting:
+			for i, p := range path {
+				if p == id {
+					return fmt.Errorf("region parent cycle: %s", strings.Join(append(path[i:], id), " -> "))
+				}
+			}
+		}
+		state[id] = visiting
+		path = append(path, id)
+		s := byID[id]
+		if _, ok := byID[s.ParentRegionID]; ok {
+			if err := visit(s.ParentRegionID); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		sorted = append(sorted, s)
+		return nil
+	}
+	for _, s := range specs {
+		if err = visit(s.ID); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
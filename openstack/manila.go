@@ -0,0 +1,236 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/sharedfilesystems/v2/sharetypes"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+)
+
+// manilaMicroversion is the first microversion supporting share type descriptions (2.41)
+// and the organizational unit of security services (2.44).
+const manilaMicroversion = "2.44"
+
+type Manila struct {
+	Client *gophercloud.ServiceClient
+}
+
+func NewManila(client *gophercloud.ServiceClient) (m *Manila) {
+	client.Microversion = manilaMicroversion
+	return &Manila{
+		Client: client,
+	}
+}
+
+func NewSharedFileSystemClient() (client *gophercloud.ServiceClient, err error) {
+	provider, err := newProviderClient()
+	if err != nil {
+		return
+	}
+	client, err = openstack.NewSharedFileSystemV2(provider, regionEndpointOpts())
+	return
+}
+
+// NewProjectSharedFileSystemClient returns a client scoped to the given project. Share networks and
+// security services are always created in the project of the token, so the seeder user needs the
+// member role in every project with share networks or security services.
+func NewProjectSharedFileSystemClient(projectID string) (client *gophercloud.ServiceClient, err error) {
+	provider, err := newProjectProviderClient(projectID)
+	if err != nil {
+		var unauthorized gophercloud.ErrDefault401
+		if errors.As(err, &unauthorized) {
+			return nil, fmt.Errorf("cannot authenticate in project %s, the seeder user needs the member role in it to seed share networks and security services: %w", projectID, err)
+		}
+		return
+	}
+	client, err = openstack.NewSharedFileSystemV2(provider, regionEndpointOpts())
+	return
+}
+
+// shareTypeCreateOpts supports descriptions and arbitrary extra specs, unlike sharetypes.CreateOpts.
+type shareTypeCreateOpts struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	IsPublic    bool              `json:"os-share-type-access:is_public"`
+	ExtraSpecs  map[string]string `json:"extra_specs"`
+}
+
+func (opts shareTypeCreateOpts) ToShareTypeCreateMap() (map[string]interface{}, error) {
+	return gophercloud.BuildRequestBody(opts, "share_type")
+}
+
+// ShareType is a manila share type including its description (microversion 2.41).
+type ShareType struct {
+	sharetypes.ShareType
+	Description string `json:"description"`
+}
+
+// ValidateShareType checks that the share type declares the required extra spec driver_handles_share_servers.
+func ValidateShareType(spec openstackstablesapccv2.ShareTypeSpec) error {
+	if spec.Specs == nil || spec.Specs.DHSS == nil {
+		return fmt.Errorf("share type %s: specs.driver_handles_share_servers is required", spec.Name)
+	}
+	if _, ok := spec.ExtraSpecs["driver_handles_share_servers"]; ok {
+		return fmt.Errorf("share type %s: driver_handles_share_servers must be declared in specs, not in extra_specs", spec.Name)
+	}
+	return nil
+}
+
+// shareTypeExtraSpecs merges the typed and the untyped extra specs of a share type.
+func shareTypeExtraSpecs(spec openstackstablesapccv2.ShareTypeSpec) map[string]string {
+	specs := make(map[string]string, len(spec.ExtraSpecs)+2)
+	for k, v := range spec.ExtraSpecs {
+		specs[k] = v
+	}
+	specs["driver_handles_share_servers"] = strconv.FormatBool(*spec.Specs.DHSS)
+	if spec.Specs.SnapshotSupport != nil {
+		specs["snapshot_support"] = strconv.FormatBool(*spec.Specs.SnapshotSupport)
+	}
+	return specs
+}
+
+// GetShareType returns the share type with the given name, or nil if it does not exist.
+func (m *Manila) GetShareType(name string) (*ShareType, error) {
+	p, err := sharetypes.List(m.Client, sharetypes.ListOpts{IsPublic: "all"}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	var s struct {
+		ShareTypes []ShareType `json:"share_types"`
+	}
+	if err = p.(sharetypes.ShareTypePage).ExtractInto(&s); err != nil {
+		return nil, err
+	}
+	for i := range s.ShareTypes {
+		if s.ShareTypes[i].Name == name {
+			return &s.ShareTypes[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// SeedShareType creates a share type and seeds its extra specs. The description and visibility of
+// a share type cannot be changed, so differences are returned as drift.
+func (m *Manila) SeedShareType(spec openstackstablesapccv2.ShareTypeSpec) (id string, drift []Drift, err error) {
+	isPublic := true
+	if spec.IsPublic != nil {
+		isPublic = *spec.IsPublic
+	}
+	specs := shareTypeExtraSpecs(spec)
+	st, err := m.GetShareType(spec.Name)
+	if err != nil {
+		return
+	}
+	if st == nil {
+		created, err := sharetypes.Create(m.Client, shareTypeCreateOpts{
+			Name:        spec.Name,
+			Description: spec.Description,
+			IsPublic:    isPublic,
+			ExtraSpecs:  specs,
+		}).Extract()
+		if err != nil {
+			return "", nil, fmt.Errorf("cannot create share type %s: %w", spec.Name, err)
+		}
+		return created.ID, nil, nil
+	}
+
+	resource := fmt.Sprintf("share type %s", spec.Name)
+	if st.Description != spec.Description {
+		drift = append(drift, Drift{Resource: resource, Field: "description", Desired: spec.Description, Actual: st.Description})
+	}
+	if st.IsPublic != isPublic {
+		drift = append(drift, Drift{Resource: resource, Field: "is_public", Desired: isPublic, Actual: st.IsPublic})
+	}
+
+	current, err := sharetypes.GetExtraSpecs(m.Client, st.ID).Extract()
+	if err != nil {
+		return st.ID, drift, err
+	}
+	changed := make(map[string]interface{})
+	for k, v := range specs {
+		if cv, ok := current[k]; !ok || !strings.EqualFold(fmt.Sprint(cv), v) {
+			changed[k] = v
+		}
+	}
+	if len(changed) > 0 {
+		if _, err = sharetypes.SetExtraSpecs(m.Client, st.ID, sharetypes.SetExtraSpecsOpts{ExtraSpecs: changed}).Extract(); err != nil {
+			return st.ID, drift, fmt.Errorf("cannot set extra specs of share type %s: %w", spec.Name, err)
+		}
+	}
+	for k := range current {
+		if _, ok := specs[k]; ok {
+			continue
+		}
+		if err = sharetypes.UnsetExtraSpecs(m.Client, st.ID, k).ExtractErr(); err != nil {
+			return st.ID, drift, fmt.Errorf("cannot delete extra spec %s of share type %s: %w", k, spec.Name, err)
+		}
+	}
+	return st.ID, drift, nil
+}
+
+// SeedShareTypeAccess grants the projects access to a private share type. If prune is set,
+// the access of all other projects is revoked. Public share types are skipped.
+func (m *Manila) SeedShareTypeAccess(name string, projectIDs []string, prune bool) (err error) {
+	st, err := m.GetShareType(name)
+	if err != nil {
+		return
+	}
+	if st == nil {
+		return fmt.Errorf("could not find share type: %s", name)
+	}
+	if st.IsPublic {
+		return
+	}
+	accesses, err := sharetypes.ShowAccess(m.Client, st.ID).Extract()
+	if err != nil {
+		return
+	}
+	granted := make(map[string]bool, len(accesses))
+	for _, a := range accesses {
+		granted[a.ProjectID] = true
+	}
+	wanted := make(map[string]bool, len(projectIDs))
+	for _, id := range projectIDs {
+		wanted[id] = true
+		if granted[id] {
+			continue
+		}
+		if err = sharetypes.AddAccess(m.Client, st.ID, sharetypes.AccessOpts{Project: id}).ExtractErr(); err != nil {
+			return fmt.Errorf("cannot grant project %s access to share type %s: %w", id, name, err)
+		}
+	}
+	if !prune {
+		return
+	}
+	for _, a := range accesses {
+		if wanted[a.ProjectID] {
+			continue
+		}
+		if err = sharetypes.RemoveAccess(m.Client, st.ID, sharetypes.AccessOpts{Project: a.ProjectID}).ExtractErr(); err != nil {
+			return fmt.Errorf("cannot revoke access of project %s to share type %s: %w", a.ProjectID, name, err)
+		}
+	}
+	return
+}
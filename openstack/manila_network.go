@@ -0,0 +1,207 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+
+	"github.com/gophercloud/gophercloud/openstack/sharedfilesystems/v2/securityservices"
+	"github.com/gophercloud/gophercloud/openstack/sharedfilesystems/v2/sharenetworks"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+)
+
+// Share networks and security services belong to the project of the token, so the Manila
+// client used for the functions below has to be scoped to the project (see NewProjectSharedFileSystemClient).
+
+// ValidateSecurityService checks the type of a security service.
+func ValidateSecurityService(spec openstackstablesapccv2.SecurityServiceSpec) error {
+	switch spec.Type {
+	case "active_directory", "kerberos", "ldap":
+		return nil
+	}
+	return fmt.Errorf("security service %s: invalid type %s: must be active_directory, kerberos or ldap", spec.Name, spec.Type)
+}
+
+// GetSecurityService returns the security service with the given name, or nil if it does not exist.
+func (m *Manila) GetSecurityService(name string) (*securityservices.SecurityService, error) {
+	p, err := securityservices.List(m.Client, securityservices.ListOpts{Name: name}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	ss, err := securityservices.ExtractSecurityServices(p)
+	if err != nil {
+		return nil, err
+	}
+	for i := range ss {
+		if ss[i].Name == name {
+			return &ss[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// SeedSecurityService creates or updates a security service. The password is only managed if it is set.
+// The type of a security service cannot be changed, so a differing type is returned as drift.
+func (m *Manila) SeedSecurityService(spec openstackstablesapccv2.SecurityServiceSpec, password string) (id string, drift []Drift, err error) {
+	ss, err := m.GetSecurityService(spec.Name)
+	if err != nil {
+		return
+	}
+	if ss == nil {
+		created, err := securityservices.Create(m.Client, securityservices.CreateOpts{
+			Type:        securityservices.SecurityServiceType(spec.Type),
+			Name:        spec.Name,
+			Description: spec.Description,
+			DNSIP:       spec.DNSIP,
+			OU:          spec.OU,
+			User:        spec.User,
+			Password:    password,
+			Domain:      spec.Domain,
+			Server:      spec.Server,
+		}).Extract()
+		if err != nil {
+			return "", nil, fmt.Errorf("cannot create security service %s: %w", spec.Name, err)
+		}
+		return created.ID, nil, nil
+	}
+
+	if ss.Type != spec.Type {
+		drift = append(drift, Drift{Resource: fmt.Sprintf("security service %s", spec.Name), Field: "type", Desired: spec.Type, Actual: ss.Type})
+	}
+	changed := false
+	update := func(desired, actual string) *string {
+		if desired == actual {
+			return nil
+		}
+		changed = true
+		return &desired
+	}
+	opts := securityservices.UpdateOpts{
+		Name:        &spec.Name,
+		Description: update(spec.Description, ss.Description),
+		DNSIP:       update(spec.DNSIP, ss.DNSIP),
+		OU:          update(spec.OU, ss.OU),
+		User:        update(spec.User, ss.User),
+		Domain:      update(spec.Domain, ss.Domain),
+		Server:      update(spec.Server, ss.Server),
+	}
+	if password != "" {
+		opts.Password = update(password, ss.Password)
+	}
+	if changed {
+		if _, err = securityservices.Update(m.Client, ss.ID, opts).Extract(); err != nil {
+			return ss.ID, drift, fmt.Errorf("cannot update security service %s: %w", spec.Name, err)
+		}
+	}
+	return ss.ID, drift, nil
+}
+
+// GetShareNetwork returns the share network with the given name, or nil if it does not exist.
+func (m *Manila) GetShareNetwork(name string) (*sharenetworks.ShareNetwork, error) {
+	p, err := sharenetworks.ListDetail(m.Client, sharenetworks.ListOpts{Name: name}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	sns, err := sharenetworks.ExtractShareNetworks(p)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sns {
+		if sns[i].Name == name {
+			return &sns[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// SeedShareNetwork creates or updates a share network on the given neutron network and subnet and
+// associates exactly the declared security services. Manila only allows to change the neutron network
+// of share networks without share servers, so a differing network or subnet is returned as drift.
+func (m *Manila) SeedShareNetwork(spec openstackstablesapccv2.ShareNetworkSpec, networkID, subnetID string) (id string, drift []Drift, err error) {
+	ids := make([]string, 0, len(spec.SecurityServices))
+	wanted := make(map[string]bool, len(spec.SecurityServices))
+	for _, name := range spec.SecurityServices {
+		ss, err := m.GetSecurityService(name)
+		if err != nil {
+			return "", nil, err
+		}
+		if ss == nil {
+			return "", nil, fmt.Errorf("share network %s: could not find security service: %s", spec.Name, name)
+		}
+		ids = append(ids, ss.ID)
+		wanted[ss.ID] = true
+	}
+
+	sn, err := m.GetShareNetwork(spec.Name)
+	if err != nil {
+		return
+	}
+	if sn == nil {
+		sn, err = sharenetworks.Create(m.Client, sharenetworks.CreateOpts{
+			Name:            spec.Name,
+			Description:     spec.Description,
+			NeutronNetID:    networkID,
+			NeutronSubnetID: subnetID,
+		}).Extract()
+		if err != nil {
+			return "", nil, fmt.Errorf("cannot create share network %s: %w", spec.Name, err)
+		}
+	} else {
+		resource := fmt.Sprintf("share network %s", spec.Name)
+		if sn.NeutronNetID != networkID {
+			drift = append(drift, Drift{Resource: resource, Field: "neutron_net_id", Desired: networkID, Actual: sn.NeutronNetID})
+		}
+		if sn.NeutronSubnetID != subnetID {
+			drift = append(drift, Drift{Resource: resource, Field: "neutron_subnet_id", Desired: subnetID, Actual: sn.NeutronSubnetID})
+		}
+		if sn.Description != spec.Description {
+			if _, err = sharenetworks.Update(m.Client, sn.ID, sharenetworks.UpdateOpts{Description: &spec.Description}).Extract(); err != nil {
+				return sn.ID, drift, fmt.Errorf("cannot update share network %s: %w", spec.Name, err)
+			}
+		}
+	}
+
+	p, err := securityservices.List(m.Client, securityservices.ListOpts{ShareNetworkID: sn.ID}).AllPages()
+	if err != nil {
+		return sn.ID, drift, err
+	}
+	current, err := securityservices.ExtractSecurityServices(p)
+	if err != nil {
+		return sn.ID, drift, err
+	}
+	associated := make(map[string]bool, len(current))
+	for _, ss := range current {
+		associated[ss.ID] = true
+		if wanted[ss.ID] {
+			continue
+		}
+		if _, err = sharenetworks.RemoveSecurityService(m.Client, sn.ID, sharenetworks.RemoveSecurityServiceOpts{SecurityServiceID: ss.ID}).Extract(); err != nil {
+			return sn.ID, drift, fmt.Errorf("cannot remove security service %s from share network %s: %w", ss.Name, spec.Name, err)
+		}
+	}
+	for i, ssID := range ids {
+		if associated[ssID] {
+			continue
+		}
+		associated[ssID] = true
+		if _, err = sharenetworks.AddSecurityService(m.Client, sn.ID, sharenetworks.AddSecurityServiceOpts{SecurityServiceID: ssID}).Extract(); err != nil {
+			return sn.ID, drift, fmt.Errorf("cannot add security service %s to share network %s: %w", spec.SecurityServices[i], spec.Name, err)
+		}
+	}
+	return sn.ID, drift, nil
+}
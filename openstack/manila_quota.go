@@ -0,0 +1,62 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+
+	"github.com/gophercloud/gophercloud"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+)
+
+// gophercloud does not support manila quota sets (yet), so the few requests the seeder needs are implemented here.
+
+func shareQuotaURL(c *gophercloud.ServiceClient, projectID string) string {
+	return c.ServiceURL("os-quota-sets", projectID)
+}
+
+func getShareQuota(c *gophercloud.ServiceClient, projectID string) (map[string]interface{}, error) {
+	var r struct {
+		QuotaSet map[string]interface{} `json:"quota_set"`
+	}
+	_, err := c.Get(shareQuotaURL(c, projectID), &r, nil)
+	return r.QuotaSet, err
+}
+
+func updateShareQuota(c *gophercloud.ServiceClient, projectID string, opts quotaSetOpts) error {
+	_, err := c.Put(shareQuotaURL(c, projectID), map[string]interface{}{"quota_set": map[string]interface{}(opts)}, nil, &gophercloud.RequestOpts{
+		OkCodes: []int{200},
+	})
+	return err
+}
+
+// SeedShareQuota updates the keys of the project's shared file system quota which differ from the spec.
+func (m *Manila) SeedShareQuota(projectID, project string, spec openstackstablesapccv2.ShareQuotaSpec) (changes []openstackstablesapccv2.QuotaChange, err error) {
+	current, err := getShareQuota(m.Client, projectID)
+	if err != nil {
+		return
+	}
+	opts, changes := diffQuota("share", project, spec, current)
+	if len(opts) == 0 {
+		return
+	}
+	if err = updateShareQuota(m.Client, projectID, opts); err != nil {
+		return nil, fmt.Errorf("cannot update share quota of project %s: %w", project, err)
+	}
+	return
+}
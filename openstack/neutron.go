@@ -0,0 +1,119 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/networks"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
+)
+
+type Neutron struct {
+	Client *gophercloud.ServiceClient
+	// Keystone resolves the projects of name@project@domain references.
+	Keystone *Keystone
+	// Octavia takes the load balancer quotas if the catalog has a load-balancer service.
+	Octavia *gophercloud.ServiceClient
+}
+
+func NewNeutron(client *gophercloud.ServiceClient, keystone *Keystone) (n *Neutron) {
+	return &Neutron{
+		Client:   client,
+		Keystone: keystone,
+	}
+}
+
+func NewNetworkClient() (client *gophercloud.ServiceClient, err error) {
+	provider, err := newProviderClient()
+	if err != nil {
+		return
+	}
+	client, err = openstack.NewNetworkV2(provider, regionEndpointOpts())
+	return
+}
+
+// NewLoadBalancerClient returns an octavia client, or nil if the catalog has no load-balancer service.
+func NewLoadBalancerClient() (client *gophercloud.ServiceClient, err error) {
+	provider, err := newProviderClient()
+	if err != nil {
+		return
+	}
+	client, err = openstack.NewLoadBalancerV2(provider, regionEndpointOpts())
+	if _, ok := err.(*gophercloud.ErrEndpointNotFound); ok {
+		return nil, nil
+	}
+	return
+}
+
+// resolveRef splits a reference of the form name or name@project@domain into the name
+// and the id of the project owning the object. A plain name refers to an object of the given project,
+// or of any project if projectID is empty.
+func (n *Neutron) resolveRef(ref, projectID string) (name, ownerID string, err error) {
+	parts := strings.Split(ref, "@")
+	switch len(parts) {
+	case 1:
+		return ref, projectID, nil
+	case 3:
+		ownerID, err = n.Keystone.GetProjectID(parts[2], parts[1])
+		return parts[0], ownerID, err
+	}
+	return "", "", fmt.Errorf("invalid reference %s: must be name or name@project@domain", ref)
+}
+
+// GetNetworkID returns the id of the network referenced by name or name@project@domain.
+func (n *Neutron) GetNetworkID(ref, projectID string) (id string, err error) {
+	name, ownerID, err := n.resolveRef(ref, projectID)
+	if err != nil {
+		return
+	}
+	p, err := networks.List(n.Client, networks.ListOpts{Name: name, ProjectID: ownerID}).AllPages()
+	if err != nil {
+		return
+	}
+	r, err := networks.ExtractNetworks(p)
+	if err != nil {
+		return
+	}
+	if len(r) != 1 {
+		return id, fmt.Errorf("could not find network: %s", ref)
+	}
+	return r[0].ID, nil
+}
+
+// GetSubnetID returns the id of the subnet referenced by name or name@project@domain.
+func (n *Neutron) GetSubnetID(ref, projectID string) (id string, err error) {
+	name, ownerID, err := n.resolveRef(ref, projectID)
+	if err != nil {
+		return
+	}
+	p, err := subnets.List(n.Client, subnets.ListOpts{Name: name, ProjectID: ownerID}).AllPages()
+	if err != nil {
+		return
+	}
+	r, err := subnets.ExtractSubnets(p)
+	if err != nil {
+		return
+	}
+	if len(r) != 1 {
+		return id, fmt.Errorf("could not find subnet: %s", ref)
+	}
+	return r[0].ID, nil
+}
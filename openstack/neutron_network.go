@@ -0,0 +1,397 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/attributestags"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/subnetpools"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/networks"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+)
+
+// Network is a neutron network including the attributes of the provider, external-net,
+// port-security, qos and vlan-transparent extensions. networks.Network cannot be embedded,
+// because its custom unmarshaller would hide the extension attributes.
+type Network struct {
+	ID                  string   `json:"id"`
+	Name                string   `json:"name"`
+	Description         string   `json:"description"`
+	ProjectID           string   `json:"project_id"`
+	AdminStateUp        bool     `json:"admin_state_up"`
+	Shared              bool     `json:"shared"`
+	Subnets             []string `json:"subnets"`
+	Tags                []string `json:"tags"`
+	NetworkType         string   `json:"provider:network_type"`
+	PhysicalNetwork     string   `json:"provider:physical_network"`
+	SegmentationID      *int     `json:"provider:segmentation_id"`
+	RouterExternal      bool     `json:"router:external"`
+	PortSecurityEnabled *bool    `json:"port_security_enabled"`
+	QosPolicyID         string   `json:"qos_policy_id"`
+	VlanTransparent     *bool    `json:"vlan_transparent"`
+}
+
+// networkOpts supports the extension attributes of networks, unlike networks.CreateOpts.
+// The provider attributes and vlan_transparent can only be set on creation.
+type networkOpts struct {
+	Name                string  `json:"name,omitempty"`
+	Description         *string `json:"description,omitempty"`
+	ProjectID           string  `json:"project_id,omitempty"`
+	AdminStateUp        *bool   `json:"admin_state_up,omitempty"`
+	Shared              *bool   `json:"shared,omitempty"`
+	RouterExternal      *bool   `json:"router:external,omitempty"`
+	PortSecurityEnabled *bool   `json:"port_security_enabled,omitempty"`
+	QosPolicyID         *string `json:"qos_policy_id,omitempty"`
+	VlanTransparent     *bool   `json:"vlan_transparent,omitempty"`
+	NetworkType         string  `json:"provider:network_type,omitempty"`
+	PhysicalNetwork     string  `json:"provider:physical_network,omitempty"`
+	SegmentationID      *int    `json:"provider:segmentation_id,omitempty"`
+}
+
+func (opts networkOpts) ToNetworkCreateMap() (map[string]interface{}, error) {
+	return gophercloud.BuildRequestBody(opts, "network")
+}
+
+func (opts networkOpts) ToNetworkUpdateMap() (map[string]interface{}, error) {
+	return gophercloud.BuildRequestBody(opts, "network")
+}
+
+// subnetOpts supports clearing list attributes of subnets, unlike subnets.UpdateOpts.
+type subnetOpts struct {
+	NetworkID       string                    `json:"network_id,omitempty"`
+	ProjectID       string                    `json:"project_id,omitempty"`
+	Name            string                    `json:"name,omitempty"`
+	Description     *string                   `json:"description,omitempty"`
+	IPVersion       int                       `json:"ip_version,omitempty"`
+	CIDR            string                    `json:"cidr,omitempty"`
+	Prefixlen       *int                      `json:"prefixlen,omitempty"`
+	SubnetPoolID    string                    `json:"subnetpool_id,omitempty"`
+	SegmentID       string                    `json:"segment_id,omitempty"`
+	IPv6AddressMode string                    `json:"ipv6_address_mode,omitempty"`
+	IPv6RAMode      string                    `json:"ipv6_ra_mode,omitempty"`
+	GatewayIP       *string                   `json:"gateway_ip,omitempty"`
+	EnableDHCP      *bool                     `json:"enable_dhcp,omitempty"`
+	DNSNameservers  *[]string                 `json:"dns_nameservers,omitempty"`
+	AllocationPools *[]subnets.AllocationPool `json:"allocation_pools,omitempty"`
+	HostRoutes      *[]subnets.HostRoute      `json:"host_routes,omitempty"`
+}
+
+func (opts subnetOpts) ToSubnetCreateMap() (map[string]interface{}, error) {
+	return gophercloud.BuildRequestBody(opts, "subnet")
+}
+
+func (opts subnetOpts) ToSubnetUpdateMap() (map[string]interface{}, error) {
+	return gophercloud.BuildRequestBody(opts, "subnet")
+}
+
+// GetNetwork returns the network of the project with the given name, or nil if it does not exist.
+func (n *Neutron) GetNetwork(projectID, name string) (*Network, error) {
+	p, err := networks.List(n.Client, networks.ListOpts{Name: name, ProjectID: projectID}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	var r []Network
+	if err = networks.ExtractNetworksInto(p, &r); err != nil {
+		return nil, err
+	}
+	if len(r) == 0 {
+		return nil, nil
+	}
+	return &r[0], nil
+}
+
+// GetSubnetPoolID returns the id of the subnet pool referenced by name or name@project@domain.
+func (n *Neutron) GetSubnetPoolID(ref, projectID string) (id string, err error) {
+	name, ownerID, err := n.resolveRef(ref, projectID)
+	if err != nil {
+		return
+	}
+	p, err := subnetpools.List(n.Client, subnetpools.ListOpts{Name: name, ProjectID: ownerID}).AllPages()
+	if err != nil {
+		return
+	}
+	r, err := subnetpools.ExtractSubnetPools(p)
+	if err != nil {
+		return
+	}
+	if len(r) != 1 {
+		return id, fmt.Errorf("could not find subnet pool: %s", ref)
+	}
+	return r[0].ID, nil
+}
+
+// ValidateNetwork checks the provider segmentation id and the subnets of a network.
+func ValidateNetwork(spec openstackstablesapccv2.NetworkSpec) error {
+	if spec.ProviderSegmentationId != "" {
+		if _, err := strconv.Atoi(spec.ProviderSegmentationId); err != nil {
+			return fmt.Errorf("network %s: invalid provider_segmentation_id %s", spec.Name, spec.ProviderSegmentationId)
+		}
+	}
+	for _, s := range spec.Subnets {
+		if err := ValidateSubnet(s); err != nil {
+			return fmt.Errorf("network %s: %w", spec.Name, err)
+		}
+	}
+	return nil
+}
+
+// SeedNetwork creates or updates a network of the project and seeds its subnets. The provider attributes
+// and vlan_transparent of a network cannot be changed, so differences are returned as drift.
+func (n *Neutron) SeedNetwork(projectID string, spec openstackstablesapccv2.NetworkSpec) (updated *Network, drift []Drift, err error) {
+	var segmentationID *int
+	if spec.ProviderSegmentationId != "" {
+		id, _ := strconv.Atoi(spec.ProviderSegmentationId)
+		segmentationID = &id
+	}
+	updated, err = n.GetNetwork(projectID, spec.Name)
+	if err != nil {
+		return
+	}
+	var qosPolicyID *string
+	if spec.QosPolicyId != "" {
+		id, err := n.GetQosPolicyID(spec.QosPolicyId, projectID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("network %s: %w", spec.Name, err)
+		}
+		qosPolicyID = &id
+	}
+
+	if updated == nil {
+		var created Network
+		err = networks.Create(n.Client, networkOpts{
+			Name:                spec.Name,
+			Description:         &spec.Description,
+			ProjectID:           projectID,
+			AdminStateUp:        spec.AdminStateUp,
+			Shared:              spec.Shared,
+			RouterExternal:      spec.RouterExternal,
+			PortSecurityEnabled: spec.PortSecurityEnabled,
+			QosPolicyID:         qosPolicyID,
+			VlanTransparent:     spec.VlanTransparent,
+			NetworkType:         spec.ProviderNetworkType,
+			PhysicalNetwork:     spec.ProviderPhysicalNetwork,
+			SegmentationID:      segmentationID,
+		}).ExtractInto(&created)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot create network %s: %w", spec.Name, err)
+		}
+		updated = &created
+	} else {
+		drift = networkDrift(spec, segmentationID, updated)
+		changed := false
+		opts := networkOpts{}
+		if updated.Description != spec.Description {
+			opts.Description, changed = &spec.Description, true
+		}
+		if spec.AdminStateUp != nil && *spec.AdminStateUp != updated.AdminStateUp {
+			opts.AdminStateUp, changed = spec.AdminStateUp, true
+		}
+		if spec.Shared != nil && *spec.Shared != updated.Shared {
+			opts.Shared, changed = spec.Shared, true
+		}
+		if spec.RouterExternal != nil && *spec.RouterExternal != updated.RouterExternal {
+			opts.RouterExternal, changed = spec.RouterExternal, true
+		}
+		if spec.PortSecurityEnabled != nil && (updated.PortSecurityEnabled == nil || *spec.PortSecurityEnabled != *updated.PortSecurityEnabled) {
+			opts.PortSecurityEnabled, changed = spec.PortSecurityEnabled, true
+		}
+		if qosPolicyID != nil && *qosPolicyID != updated.QosPolicyID {
+			opts.QosPolicyID, changed = qosPolicyID, true
+		}
+		if changed {
+			var network Network
+			if err = networks.Update(n.Client, updated.ID, opts).ExtractInto(&network); err != nil {
+				return updated, drift, fmt.Errorf("cannot update network %s: %w", spec.Name, err)
+			}
+			updated = &network
+		}
+	}
+	if err = n.seedTags("networks", updated.ID, spec.Tags, updated.Tags); err != nil {
+		return updated, drift, fmt.Errorf("cannot set tags of network %s: %w", spec.Name, err)
+	}
+
+	for _, s := range spec.Subnets {
+		subnetDrift, err := n.SeedSubnet(projectID, updated.ID, s)
+		drift = append(drift, subnetDrift...)
+		if err != nil {
+			return updated, drift, err
+		}
+	}
+	return
+}
+
+func networkDrift(spec openstackstablesapccv2.NetworkSpec, segmentationID *int, network *Network) (drift []Drift) {
+	resource := fmt.Sprintf("network %s", spec.Name)
+	if spec.ProviderNetworkType != "" && spec.ProviderNetworkType != network.NetworkType {
+		drift = append(drift, Drift{Resource: resource, Field: "provider_network_type", Desired: spec.ProviderNetworkType, Actual: network.NetworkType})
+	}
+	if spec.ProviderPhysicalNetwork != "" && spec.ProviderPhysicalNetwork != network.PhysicalNetwork {
+		drift = append(drift, Drift{Resource: resource, Field: "provider_physical_network", Desired: spec.ProviderPhysicalNetwork, Actual: network.PhysicalNetwork})
+	}
+	if segmentationID != nil && (network.SegmentationID == nil || *segmentationID != *network.SegmentationID) {
+		var actual interface{}
+		if network.SegmentationID != nil {
+			actual = *network.SegmentationID
+		}
+		drift = append(drift, Drift{Resource: resource, Field: "provider_segmentation_id", Desired: *segmentationID, Actual: actual})
+	}
+	if spec.VlanTransparent != nil && (network.VlanTransparent == nil || *spec.VlanTransparent != *network.VlanTransparent) {
+		drift = append(drift, Drift{Resource: resource, Field: "vlan_transparent", Desired: *spec.VlanTransparent, Actual: network.VlanTransparent != nil && *network.VlanTransparent})
+	}
+	return
+}
+
+// getSubnet returns the subnet of the network with the given name, or nil if it does not exist.
+func (n *Neutron) getSubnet(networkID, name string) (*subnets.Subnet, error) {
+	p, err := subnets.List(n.Client, subnets.ListOpts{Name: name, NetworkID: networkID}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	r, err := subnets.ExtractSubnets(p)
+	if err != nil || len(r) == 0 {
+		return nil, err
+	}
+	return &r[0], nil
+}
+
+// SeedSubnet creates or updates a subnet of the network. Only declared dns name servers, allocation pools
+// and host routes are managed. The cidr, ip version, ipv6 modes and subnet pool of a subnet cannot be changed,
+// so differences are returned as drift.
+func (n *Neutron) SeedSubnet(projectID, networkID string, spec openstackstablesapccv2.SubnetSpec) (drift []Drift, err error) {
+	pools, _ := parseAllocationPools(spec.AllocationPools, nil)
+	routes, _ := parseHostRoutes(spec.HostRoutes, nil)
+	ipVersion := spec.IpVersion
+	if ipVersion == 0 {
+		ipVersion = 4
+		if strings.Contains(spec.CIDR, ":") {
+			ipVersion = 6
+		}
+	}
+	subnetPoolID := spec.SubnetPoolId
+	if subnetPoolID == "" && spec.SubnetPool != "" {
+		if subnetPoolID, err = n.GetSubnetPoolID(spec.SubnetPool, projectID); err != nil {
+			return
+		}
+	}
+	opts := subnetOpts{
+		Description: &spec.Description,
+		EnableDHCP:  spec.EnableDHCP,
+	}
+	if spec.GatewayIP != "" {
+		opts.GatewayIP = &spec.GatewayIP
+	}
+	if spec.DNSNameServers != nil {
+		opts.DNSNameservers = &spec.DNSNameServers
+	}
+	if spec.AllocationPools != nil {
+		opts.AllocationPools = &pools
+	}
+	if spec.HostRoutes != nil {
+		opts.HostRoutes = &routes
+	}
+
+	s, err := n.getSubnet(networkID, spec.Name)
+	if err != nil {
+		return
+	}
+	if s == nil {
+		opts.NetworkID, opts.ProjectID, opts.Name = networkID, projectID, spec.Name
+		opts.IPVersion, opts.CIDR, opts.Prefixlen = ipVersion, spec.CIDR, spec.Prefixlen
+		opts.SubnetPoolID, opts.SegmentID = subnetPoolID, spec.SegmentlId
+		opts.IPv6AddressMode, opts.IPv6RAMode = spec.IPV6AddressMode, spec.IPV6RaMode
+		if s, err = subnets.Create(n.Client, opts).Extract(); err != nil {
+			return nil, fmt.Errorf("cannot create subnet %s: %w", spec.Name, err)
+		}
+	} else {
+		resource := fmt.Sprintf("subnet %s", spec.Name)
+		if spec.CIDR != "" && spec.CIDR != s.CIDR {
+			drift = append(drift, Drift{Resource: resource, Field: "cidr", Desired: spec.CIDR, Actual: s.CIDR})
+		}
+		if ipVersion != s.IPVersion {
+			drift = append(drift, Drift{Resource: resource, Field: "ip_version", Desired: ipVersion, Actual: s.IPVersion})
+		}
+		if spec.IPV6AddressMode != s.IPv6AddressMode {
+			drift = append(drift, Drift{Resource: resource, Field: "ipv6_address_mode", Desired: spec.IPV6AddressMode, Actual: s.IPv6AddressMode})
+		}
+		if spec.IPV6RaMode != s.IPv6RAMode {
+			drift = append(drift, Drift{Resource: resource, Field: "ipv6_ra_mode", Desired: spec.IPV6RaMode, Actual: s.IPv6RAMode})
+		}
+		if subnetPoolID != "" && subnetPoolID != s.SubnetPoolID {
+			drift = append(drift, Drift{Resource: resource, Field: "subnetpool_id", Desired: subnetPoolID, Actual: s.SubnetPoolID})
+		}
+
+		if s.Description == spec.Description {
+			opts.Description = nil
+		}
+		if opts.EnableDHCP != nil && *opts.EnableDHCP == s.EnableDHCP {
+			opts.EnableDHCP = nil
+		}
+		if opts.GatewayIP != nil && *opts.GatewayIP == s.GatewayIP {
+			opts.GatewayIP = nil
+		}
+		if opts.DNSNameservers != nil && stringsEqual(*opts.DNSNameservers, s.DNSNameservers) {
+			opts.DNSNameservers = nil
+		}
+		if opts.AllocationPools != nil && allocationPoolsEqual(pools, s.AllocationPools) {
+			opts.AllocationPools = nil
+		}
+		if opts.HostRoutes != nil && hostRoutesEqual(routes, s.HostRoutes) {
+			opts.HostRoutes = nil
+		}
+		if opts != (subnetOpts{}) {
+			if s, err = subnets.Update(n.Client, s.ID, opts).Extract(); err != nil {
+				return drift, fmt.Errorf("cannot update subnet %s: %w", spec.Name, err)
+			}
+		}
+	}
+	if err = n.seedTags("subnets", s.ID, spec.Tags, s.Tags); err != nil {
+		return drift, fmt.Errorf("cannot set tags of subnet %s: %w", spec.Name, err)
+	}
+	return
+}
+
+// stringsEqual compares two lists of strings, treating nil and empty lists as equal.
+func stringsEqual(a, b []string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// seedTags replaces the tags of a neutron resource, if they are declared and differ from the current ones.
+func (n *Neutron) seedTags(resourceType, id string, tags, current []string) error {
+	if tags == nil {
+		return nil
+	}
+	desired := append([]string(nil), tags...)
+	sort.Strings(desired)
+	actual := append([]string(nil), current...)
+	sort.Strings(actual)
+	if stringsEqual(desired, actual) {
+		return nil
+	}
+	_, err := attributestags.ReplaceAll(n.Client, resourceType, id, attributestags.ReplaceAllOpts{Tags: tags}).Extract()
+	return err
+}
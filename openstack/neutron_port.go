@@ -0,0 +1,310 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+)
+
+// ValidatePort checks the network and the addresses of a port.
+func ValidatePort(spec openstackstablesapccv2.PortSpec) error {
+	if spec.Network == "" {
+		return fmt.Errorf("port %s: no network", spec.Name)
+	}
+	for _, ip := range spec.FixedIPs {
+		if ip.Subnet == "" {
+			return fmt.Errorf("port %s: fixed ip %s without subnet", spec.Name, ip.IPAddress)
+		}
+		if ip.IPAddress != "" && net.ParseIP(ip.IPAddress) == nil {
+			return fmt.Errorf("port %s: invalid ip_address %s", spec.Name, ip.IPAddress)
+		}
+	}
+	if spec.MACAddress != "" {
+		if _, err := net.ParseMAC(spec.MACAddress); err != nil {
+			return fmt.Errorf("port %s: invalid mac_address %s", spec.Name, spec.MACAddress)
+		}
+	}
+	for _, pair := range spec.AllowedAddressPairs {
+		if _, _, err := net.ParseCIDR(pair.IPAddress); err != nil && net.ParseIP(pair.IPAddress) == nil {
+			return fmt.Errorf("port %s: invalid allowed address pair ip_address %s", spec.Name, pair.IPAddress)
+		}
+		if pair.MACAddress != "" {
+			if _, err := net.ParseMAC(pair.MACAddress); err != nil {
+				return fmt.Errorf("port %s: invalid allowed address pair mac_address %s", spec.Name, pair.MACAddress)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateFloatingIP checks the addresses and the network of a floating ip.
+func ValidateFloatingIP(spec openstackstablesapccv2.FloatingIPSpec) error {
+	if net.ParseIP(spec.FloatingIPAddress) == nil {
+		return fmt.Errorf("floating ip %s: invalid floating_ip_address", spec.FloatingIPAddress)
+	}
+	if spec.FloatingNetwork == "" {
+		return fmt.Errorf("floating ip %s: no floating_network", spec.FloatingIPAddress)
+	}
+	if spec.FixedIPAddress != "" {
+		if spec.Port == "" {
+			return fmt.Errorf("floating ip %s: fixed_ip_address without port", spec.FloatingIPAddress)
+		}
+		if net.ParseIP(spec.FixedIPAddress) == nil {
+			return fmt.Errorf("floating ip %s: invalid fixed_ip_address %s", spec.FloatingIPAddress, spec.FixedIPAddress)
+		}
+	}
+	return nil
+}
+
+// GetPort returns the port of the project with the given name, or nil if it does not exist.
+func (n *Neutron) GetPort(projectID, name string) (*ports.Port, error) {
+	p, err := ports.List(n.Client, ports.ListOpts{Name: name, ProjectID: projectID}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	r, err := ports.ExtractPorts(p)
+	if err != nil || len(r) == 0 {
+		return nil, err
+	}
+	return &r[0], nil
+}
+
+// SeedPort creates or updates a port of the project. Declared fixed ips without an address keep their
+// current address. The network and mac address of a port cannot be changed, so differences are returned as drift.
+func (n *Neutron) SeedPort(projectID string, spec openstackstablesapccv2.PortSpec) (port *ports.Port, drift []Drift, err error) {
+	networkID, err := n.GetNetworkID(spec.Network, projectID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("port %s: %w", spec.Name, err)
+	}
+	fixedIPs := make([]ports.IP, len(spec.FixedIPs))
+	for i, ip := range spec.FixedIPs {
+		if fixedIPs[i].SubnetID, err = n.GetSubnetID(ip.Subnet, projectID); err != nil {
+			return nil, nil, fmt.Errorf("port %s: %w", spec.Name, err)
+		}
+		fixedIPs[i].IPAddress = ip.IPAddress
+	}
+	pairs := make([]ports.AddressPair, len(spec.AllowedAddressPairs))
+	for i, pair := range spec.AllowedAddressPairs {
+		pairs[i] = ports.AddressPair{IPAddress: pair.IPAddress, MACAddress: pair.MACAddress}
+	}
+
+	port, err = n.GetPort(projectID, spec.Name)
+	if err != nil {
+		return
+	}
+	if port == nil {
+		opts := ports.CreateOpts{
+			NetworkID:           networkID,
+			Name:                spec.Name,
+			Description:         spec.Description,
+			ProjectID:           projectID,
+			MACAddress:          spec.MACAddress,
+			AllowedAddressPairs: pairs,
+		}
+		if len(fixedIPs) > 0 {
+			opts.FixedIPs = fixedIPs
+		}
+		if port, err = ports.Create(n.Client, opts).Extract(); err != nil {
+			return nil, nil, fmt.Errorf("cannot create port %s: %w", spec.Name, err)
+		}
+	} else {
+		resource := fmt.Sprintf("port %s", spec.Name)
+		if networkID != port.NetworkID {
+			drift = append(drift, Drift{Resource: resource, Field: "network", Desired: networkID, Actual: port.NetworkID})
+		}
+		if spec.MACAddress != "" && !strings.EqualFold(spec.MACAddress, port.MACAddress) {
+			drift = append(drift, Drift{Resource: resource, Field: "mac_address", Desired: spec.MACAddress, Actual: port.MACAddress})
+		}
+		changed := false
+		opts := ports.UpdateOpts{}
+		if port.Description != spec.Description {
+			opts.Description, changed = &spec.Description, true
+		}
+		if len(fixedIPs) > 0 {
+			if ips, differ := portFixedIPs(fixedIPs, port.FixedIPs); differ {
+				opts.FixedIPs, changed = ips, true
+			}
+		}
+		if !addressPairsEqual(pairs, port.AllowedAddressPairs, port.MACAddress) {
+			opts.AllowedAddressPairs, changed = &pairs, true
+		}
+		if changed {
+			if port, err = ports.Update(n.Client, port.ID, opts).Extract(); err != nil {
+				return port, drift, fmt.Errorf("cannot update port %s: %w", spec.Name, err)
+			}
+		}
+	}
+	if err = n.seedTags("ports", port.ID, spec.Tags, port.Tags); err != nil {
+		return port, drift, fmt.Errorf("cannot set tags of port %s: %w", spec.Name, err)
+	}
+	return
+}
+
+// portFixedIPs returns the fixed ips to request for the declared ones and whether they differ from the current ones.
+// Declared fixed ips without an address take a current address of their subnet, so that it is not reallocated.
+func portFixedIPs(desired, current []ports.IP) (r []ports.IP, changed bool) {
+	used := make([]bool, len(current))
+	r = make([]ports.IP, len(desired))
+	copy(r, desired)
+	match := func(ip ports.IP) bool {
+		for j, c := range current {
+			if !used[j] && c.SubnetID == ip.SubnetID && (ip.IPAddress == "" || net.ParseIP(c.IPAddress).Equal(net.ParseIP(ip.IPAddress))) {
+				used[j] = true
+				return true
+			}
+		}
+		return false
+	}
+	// addresses are matched first, so that fixed ips without an address do not take them
+	for _, ip := range r {
+		if ip.IPAddress != "" && !match(ip) {
+			changed = true
+		}
+	}
+	for i, ip := range r {
+		if ip.IPAddress != "" {
+			continue
+		}
+		for j, c := range current {
+			if !used[j] && c.SubnetID == ip.SubnetID {
+				used[j] = true
+				r[i].IPAddress = c.IPAddress
+				break
+			}
+		}
+		if r[i].IPAddress == "" {
+			changed = true
+		}
+	}
+	for _, u := range used {
+		if !u {
+			changed = true
+		}
+	}
+	return
+}
+
+// addressPairsEqual compares allowed address pairs as sets. Pairs without a mac address use the one of the port.
+func addressPairsEqual(a, b []ports.AddressPair, macAddress string) bool {
+	key := func(p ports.AddressPair) string {
+		mac := p.MACAddress
+		if mac == "" {
+			mac = macAddress
+		}
+		return p.IPAddress + "/" + strings.ToLower(mac)
+	}
+	keys := make(map[string]int, len(a))
+	for _, p := range a {
+		keys[key(p)]++
+	}
+	for _, p := range b {
+		keys[key(p)]--
+	}
+	for _, c := range keys {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// GetFloatingIP returns the floating ip with the given address, or nil if it is not allocated.
+func (n *Neutron) GetFloatingIP(address string) (*floatingips.FloatingIP, error) {
+	p, err := floatingips.List(n.Client, floatingips.ListOpts{FloatingIP: address}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	r, err := floatingips.ExtractFloatingIPs(p)
+	if err != nil || len(r) == 0 {
+		return nil, err
+	}
+	return &r[0], nil
+}
+
+// SeedFloatingIP allocates the floating ip address for the project, or updates the floating ip if the project
+// has allocated it already. The floating network and subnet are looked up in all projects, if they are referenced
+// by plain names. A floating ip is only associated with a port if the port is declared. The network of a floating ip
+// cannot be changed, so a difference is returned as drift.
+func (n *Neutron) SeedFloatingIP(projectID string, spec openstackstablesapccv2.FloatingIPSpec) (fip *floatingips.FloatingIP, drift []Drift, err error) {
+	networkID, err := n.GetNetworkID(spec.FloatingNetwork, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("floating ip %s: %w", spec.FloatingIPAddress, err)
+	}
+	portID := ""
+	if spec.Port != "" {
+		port, err := n.GetPort(projectID, spec.Port)
+		if err != nil {
+			return nil, nil, err
+		}
+		if port == nil {
+			return nil, nil, fmt.Errorf("floating ip %s: could not find port: %s", spec.FloatingIPAddress, spec.Port)
+		}
+		portID = port.ID
+	}
+
+	fip, err = n.GetFloatingIP(spec.FloatingIPAddress)
+	if err != nil {
+		return
+	}
+	if fip == nil {
+		opts := floatingips.CreateOpts{
+			Description:       spec.Description,
+			FloatingNetworkID: networkID,
+			FloatingIP:        spec.FloatingIPAddress,
+			PortID:            portID,
+			FixedIP:           spec.FixedIPAddress,
+			ProjectID:         projectID,
+		}
+		if spec.Subnet != "" {
+			if opts.SubnetID, err = n.GetSubnetID(spec.Subnet, ""); err != nil {
+				return nil, nil, fmt.Errorf("floating ip %s: %w", spec.FloatingIPAddress, err)
+			}
+		}
+		if fip, err = floatingips.Create(n.Client, opts).Extract(); err != nil {
+			return nil, nil, fmt.Errorf("cannot create floating ip %s: %w", spec.FloatingIPAddress, err)
+		}
+		return
+	}
+
+	if fip.ProjectID != projectID {
+		return nil, nil, fmt.Errorf("floating ip %s is allocated by project %s", spec.FloatingIPAddress, fip.ProjectID)
+	}
+	if networkID != fip.FloatingNetworkID {
+		drift = append(drift, Drift{Resource: fmt.Sprintf("floating ip %s", spec.FloatingIPAddress), Field: "floating_network", Desired: networkID, Actual: fip.FloatingNetworkID})
+	}
+	changed := false
+	opts := floatingips.UpdateOpts{}
+	if fip.Description != spec.Description {
+		opts.Description, changed = &spec.Description, true
+	}
+	if portID != "" && (portID != fip.PortID || spec.FixedIPAddress != "" && spec.FixedIPAddress != fip.FixedIP) {
+		opts.PortID, opts.FixedIP, changed = &portID, spec.FixedIPAddress, true
+	}
+	if changed {
+		if fip, err = floatingips.Update(n.Client, fip.ID, opts).Extract(); err != nil {
+			return fip, drift, fmt.Errorf("cannot update floating ip %s: %w", spec.FloatingIPAddress, err)
+		}
+	}
+	return
+}
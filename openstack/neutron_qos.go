@@ -0,0 +1,234 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/qos/policies"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/qos/rules"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+)
+
+// validDSCPMarks are the DSCP marks neutron accepts.
+var validDSCPMarks = map[int]bool{
+	0: true, 8: true, 10: true, 12: true, 14: true, 16: true, 18: true, 20: true, 22: true, 24: true, 26: true,
+	28: true, 30: true, 32: true, 34: true, 36: true, 38: true, 40: true, 46: true, 48: true, 56: true,
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12}$`)
+
+// qosRuleKey identifies a rule of a qos policy by its type and direction. Policies can have one rule of each.
+func qosRuleKey(ruleType, direction string) string {
+	if ruleType == "dscp_marking" {
+		return ruleType
+	}
+	if direction == "" {
+		direction = "egress"
+	}
+	return ruleType + "/" + direction
+}
+
+// ValidateQosPolicy checks the rules of a qos policy.
+func ValidateQosPolicy(spec openstackstablesapccv2.QosPolicySpec) error {
+	keys := make(map[string]bool, len(spec.Rules))
+	for _, r := range spec.Rules {
+		switch r.Type {
+		case "bandwidth_limit":
+			if r.MaxKbps <= 0 {
+				return fmt.Errorf("qos policy %s: bandwidth_limit rule needs max_kbps", spec.Name)
+			}
+		case "minimum_bandwidth":
+			if r.MinKbps <= 0 {
+				return fmt.Errorf("qos policy %s: minimum_bandwidth rule needs min_kbps", spec.Name)
+			}
+		case "dscp_marking":
+			if !validDSCPMarks[r.DSCPMark] {
+				return fmt.Errorf("qos policy %s: invalid dscp_mark %d", spec.Name, r.DSCPMark)
+			}
+		default:
+			return fmt.Errorf("qos policy %s: invalid rule type %s", spec.Name, r.Type)
+		}
+		if r.Direction != "" && r.Direction != "egress" && r.Direction != "ingress" {
+			return fmt.Errorf("qos policy %s: invalid direction %s", spec.Name, r.Direction)
+		}
+		key := qosRuleKey(r.Type, r.Direction)
+		if keys[key] {
+			return fmt.Errorf("qos policy %s: more than one %s rule", spec.Name, key)
+		}
+		keys[key] = true
+	}
+	return nil
+}
+
+// GetQosPolicy returns the qos policy of the project with the given name, or nil if it does not exist.
+// If projectID is empty, the name has to be unique in all projects.
+func (n *Neutron) GetQosPolicy(projectID, name string) (*policies.Policy, error) {
+	p, err := policies.List(n.Client, policies.ListOpts{Name: name, ProjectID: projectID}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	r, err := policies.ExtractPolicies(p)
+	if err != nil || len(r) == 0 {
+		return nil, err
+	}
+	if len(r) > 1 {
+		return nil, fmt.Errorf("found %d qos policies named %s", len(r), name)
+	}
+	return &r[0], nil
+}
+
+// GetQosPolicyID returns the id of the qos policy referenced by id, name or name@project@domain.
+// Plain names are looked up in the given project first and then in all projects, because qos policies
+// are usually shared by the project of the seeder.
+func (n *Neutron) GetQosPolicyID(ref, projectID string) (id string, err error) {
+	if uuidPattern.MatchString(ref) {
+		return ref, nil
+	}
+	name, ownerID, err := n.resolveRef(ref, projectID)
+	if err != nil {
+		return
+	}
+	policy, err := n.GetQosPolicy(ownerID, name)
+	if err == nil && policy == nil && ownerID != "" && ref == name {
+		policy, err = n.GetQosPolicy("", name)
+	}
+	if err != nil {
+		return
+	}
+	if policy == nil {
+		return "", fmt.Errorf("could not find qos policy: %s", ref)
+	}
+	return policy.ID, nil
+}
+
+// SeedQosPolicy creates or updates a qos policy of the project and its rules. The rules of the policy
+// are managed authoritatively, undeclared rules are deleted.
+func (n *Neutron) SeedQosPolicy(projectID string, spec openstackstablesapccv2.QosPolicySpec) (id string, err error) {
+	policy, err := n.GetQosPolicy(projectID, spec.Name)
+	if err != nil {
+		return
+	}
+	if policy == nil {
+		opts := policies.CreateOpts{
+			Name:        spec.Name,
+			Description: spec.Description,
+			ProjectID:   projectID,
+			Shared:      spec.Shared != nil && *spec.Shared,
+			IsDefault:   spec.IsDefault != nil && *spec.IsDefault,
+		}
+		if policy, err = policies.Create(n.Client, opts).Extract(); err != nil {
+			return "", fmt.Errorf("cannot create qos policy %s: %w", spec.Name, err)
+		}
+	} else {
+		changed := false
+		opts := policies.UpdateOpts{}
+		if policy.Description != spec.Description {
+			opts.Description, changed = &spec.Description, true
+		}
+		if spec.Shared != nil && *spec.Shared != policy.Shared {
+			opts.Shared, changed = spec.Shared, true
+		}
+		if spec.IsDefault != nil && *spec.IsDefault != policy.IsDefault {
+			opts.IsDefault, changed = spec.IsDefault, true
+		}
+		if changed {
+			if _, err = policies.Update(n.Client, policy.ID, opts).Extract(); err != nil {
+				return policy.ID, fmt.Errorf("cannot update qos policy %s: %w", spec.Name, err)
+			}
+		}
+	}
+	return policy.ID, n.seedQosRules(policy, spec)
+}
+
+// seedQosRules creates the declared rules the policy lacks, updates the changed ones and deletes all others.
+func (n *Neutron) seedQosRules(policy *policies.Policy, spec openstackstablesapccv2.QosPolicySpec) (err error) {
+	current := make(map[string]map[string]interface{}, len(policy.Rules))
+	for _, r := range policy.Rules {
+		ruleType, _ := r["type"].(string)
+		direction, _ := r["direction"].(string)
+		current[qosRuleKey(ruleType, direction)] = r
+	}
+	wanted := make(map[string]bool, len(spec.Rules))
+	for _, r := range spec.Rules {
+		key := qosRuleKey(r.Type, r.Direction)
+		wanted[key] = true
+		c, ok := current[key]
+		ruleID, _ := c["id"].(string)
+		switch r.Type {
+		case "bandwidth_limit":
+			if !ok {
+				opts := rules.CreateBandwidthLimitRuleOpts{MaxKBps: r.MaxKbps, MaxBurstKBps: r.MaxBurstKbps, Direction: r.Direction}
+				_, err = rules.CreateBandwidthLimitRule(n.Client, policy.ID, opts).ExtractBandwidthLimitRule()
+			} else if intValue(c["max_kbps"]) != r.MaxKbps || intValue(c["max_burst_kbps"]) != r.MaxBurstKbps {
+				opts := rules.UpdateBandwidthLimitRuleOpts{MaxKBps: &r.MaxKbps, MaxBurstKBps: &r.MaxBurstKbps}
+				_, err = rules.UpdateBandwidthLimitRule(n.Client, policy.ID, ruleID, opts).ExtractBandwidthLimitRule()
+			}
+		case "minimum_bandwidth":
+			if !ok {
+				opts := rules.CreateMinimumBandwidthRuleOpts{MinKBps: r.MinKbps, Direction: r.Direction}
+				_, err = rules.CreateMinimumBandwidthRule(n.Client, policy.ID, opts).ExtractMinimumBandwidthRule()
+			} else if intValue(c["min_kbps"]) != r.MinKbps {
+				opts := rules.UpdateMinimumBandwidthRuleOpts{MinKBps: &r.MinKbps}
+				_, err = rules.UpdateMinimumBandwidthRule(n.Client, policy.ID, ruleID, opts).ExtractMinimumBandwidthRule()
+			}
+		case "dscp_marking":
+			if !ok {
+				opts := rules.CreateDSCPMarkingRuleOpts{DSCPMark: r.DSCPMark}
+				_, err = rules.CreateDSCPMarkingRule(n.Client, policy.ID, opts).ExtractDSCPMarkingRule()
+			} else if intValue(c["dscp_mark"]) != r.DSCPMark {
+				opts := rules.UpdateDSCPMarkingRuleOpts{DSCPMark: &r.DSCPMark}
+				_, err = rules.UpdateDSCPMarkingRule(n.Client, policy.ID, ruleID, opts).ExtractDSCPMarkingRule()
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("cannot seed %s rule of qos policy %s: %w", key, spec.Name, err)
+		}
+	}
+	for _, r := range policy.Rules {
+		ruleType, _ := r["type"].(string)
+		direction, _ := r["direction"].(string)
+		key := qosRuleKey(ruleType, direction)
+		if wanted[key] {
+			continue
+		}
+		ruleID, _ := r["id"].(string)
+		switch ruleType {
+		case "bandwidth_limit":
+			err = rules.DeleteBandwidthLimitRule(n.Client, policy.ID, ruleID).ExtractErr()
+		case "minimum_bandwidth":
+			err = rules.DeleteMinimumBandwidthRule(n.Client, policy.ID, ruleID).ExtractErr()
+		case "dscp_marking":
+			err = rules.DeleteDSCPMarkingRule(n.Client, policy.ID, ruleID).ExtractErr()
+		default:
+			// rule types the seeder does not manage (e.g. minimum_packet_rate) are left alone
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("cannot delete %s rule of qos policy %s: %w", key, spec.Name, err)
+		}
+	}
+	return
+}
+
+// intValue returns the value of a number decoded from JSON as int, or 0 if it is not a number.
+func intValue(v interface{}) int {
+	f, _ := v.(float64)
+	return int(f)
+}
@@ -0,0 +1,92 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"encoding/json"
+	"fmt"
+
+	lbquotas "github.com/gophercloud/gophercloud/openstack/loadbalancer/v2/quotas"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/quotas"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+)
+
+// octaviaQuotaKeys maps the load balancer keys of the network quota to the keys of the octavia quota API.
+var octaviaQuotaKeys = map[string]string{
+	"healthmonitor": "health_monitor",
+	"l7policy":      "l7policy",
+	"listener":      "listener",
+	"loadbalancer":  "load_balancer",
+}
+
+// quotaOpts is a partial quota update of neutron or octavia. Unlike the other quota APIs,
+// they expect the quota in "quota" instead of "quota_set".
+type quotaOpts quotaSetOpts
+
+func (opts quotaOpts) ToQuotaUpdateMap() (map[string]interface{}, error) {
+	return map[string]interface{}{"quota": map[string]interface{}(opts)}, nil
+}
+
+// SeedNetworkQuota updates the keys of the project's network quota which differ from the spec.
+// The load balancer keys are seeded through octavia if it is available, or through neutron-lbaas otherwise.
+func (n *Neutron) SeedNetworkQuota(projectID, project string, spec openstackstablesapccv2.NetworkQuotaSpec) (changes []openstackstablesapccv2.QuotaChange, err error) {
+	var desired map[string]interface{}
+	b, _ := json.Marshal(spec)
+	json.Unmarshal(b, &desired)
+	lb := make(map[string]interface{})
+	if n.Octavia != nil {
+		for k, key := range octaviaQuotaKeys {
+			if v, ok := desired[k]; ok {
+				lb[key] = v
+				delete(desired, k)
+			}
+		}
+	}
+
+	if len(desired) > 0 {
+		var current struct {
+			Quota map[string]interface{} `json:"quota"`
+		}
+		if err = quotas.Get(n.Client, projectID).ExtractInto(&current); err != nil {
+			return
+		}
+		opts, c := diffQuota("network", project, desired, current.Quota)
+		if len(opts) > 0 {
+			if _, err = quotas.Update(n.Client, projectID, quotaOpts(opts)).Extract(); err != nil {
+				return nil, fmt.Errorf("cannot update network quota of project %s: %w", project, err)
+			}
+			changes = append(changes, c...)
+		}
+	}
+	if len(lb) > 0 {
+		var current struct {
+			Quota map[string]interface{} `json:"quota"`
+		}
+		if err = lbquotas.Get(n.Octavia, projectID).ExtractInto(&current); err != nil {
+			return
+		}
+		opts, c := diffQuota("load-balancer", project, lb, current.Quota)
+		if len(opts) > 0 {
+			if _, err = lbquotas.Update(n.Octavia, projectID, quotaOpts(opts)).Extract(); err != nil {
+				return changes, fmt.Errorf("cannot update load balancer quota of project %s: %w", project, err)
+			}
+			changes = append(changes, c...)
+		}
+	}
+	return
+}
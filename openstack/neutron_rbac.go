@@ -0,0 +1,192 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/rbacpolicies"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+)
+
+// rbacObjectCollection is the API collection of the objects of an RBAC object type.
+type rbacObjectCollection struct {
+	path, key string
+}
+
+// rbacObjectCollections maps the object types neutron supports RBAC policies for to their collections.
+var rbacObjectCollections = map[string]rbacObjectCollection{
+	"address_group":  {"address-groups", "address_groups"},
+	"address_scope":  {"address-scopes", "address_scopes"},
+	"network":        {"networks", "networks"},
+	"qos_policy":     {"qos/policies", "policies"},
+	"security_group": {"security-groups", "security_groups"},
+	"subnetpool":     {"subnetpools", "subnetpools"},
+}
+
+// ValidateRBACPolicy checks the object type, action and target of an RBAC policy.
+func ValidateRBACPolicy(spec openstackstablesapccv2.RBACPolicySpec) error {
+	if _, ok := rbacObjectCollections[spec.ObjectType]; !ok {
+		return fmt.Errorf("rbac policy %s: invalid object_type %s", spec.ObjectName, spec.ObjectType)
+	}
+	switch rbacpolicies.PolicyAction(spec.Action) {
+	case rbacpolicies.ActionAccessShared:
+	case rbacpolicies.ActionAccessExternal:
+		if spec.ObjectType != "network" {
+			return fmt.Errorf("rbac policy %s: action %s is only supported for networks", spec.ObjectName, spec.Action)
+		}
+	default:
+		return fmt.Errorf("rbac policy %s: invalid action %s", spec.ObjectName, spec.Action)
+	}
+	if spec.ObjectName == "" {
+		return fmt.Errorf("rbac policy of %s: no object_name", spec.ObjectType)
+	}
+	if spec.TargetTenantName != "*" && len(strings.Split(spec.TargetTenantName, "@")) != 2 {
+		return fmt.Errorf("rbac policy %s: invalid target_tenant_name %s: must be project@domain or *", spec.ObjectName, spec.TargetTenantName)
+	}
+	return nil
+}
+
+// GetRBACObjectID returns the id of the object of an RBAC policy referenced by name or name@project@domain.
+// Plain names are looked up in all projects.
+func (n *Neutron) GetRBACObjectID(objectType, ref string) (id string, err error) {
+	c, ok := rbacObjectCollections[objectType]
+	if !ok {
+		return "", fmt.Errorf("invalid object type %s", objectType)
+	}
+	name, ownerID, err := n.resolveRef(ref, "")
+	if err != nil {
+		return
+	}
+	query := url.Values{"name": {name}, "fields": {"id"}}
+	if ownerID != "" {
+		query.Set("project_id", ownerID)
+	}
+	var r map[string][]struct {
+		ID string `json:"id"`
+	}
+	if _, err = n.Client.Get(n.Client.ServiceURL(c.path)+"?"+query.Encode(), &r, nil); err != nil {
+		return
+	}
+	if len(r[c.key]) != 1 {
+		return "", &NotFoundError{Kind: objectType, Name: ref}
+	}
+	return r[c.key][0].ID, nil
+}
+
+// GetRBACTargetID returns the id of the target project (project@domain) of an RBAC policy, or * for all projects.
+func (n *Neutron) GetRBACTargetID(target string) (string, error) {
+	if target == "*" {
+		return target, nil
+	}
+	parts := strings.Split(target, "@")
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid target %s: must be project@domain or *", target)
+	}
+	return n.Keystone.GetProjectID(parts[1], parts[0])
+}
+
+// SeedRBACPolicies grants the target projects the action on the object. If prune is set, the RBAC policies
+// of the object and action for all other targets are deleted. The returned targets have been revoked.
+// Neutron grants shared objects (networks, qos policies, subnet pools and address scopes) and external
+// networks to all projects (*) itself, these policies are never deleted.
+func (n *Neutron) SeedRBACPolicies(objectType, objectID, action string, targetIDs []string, prune bool) (revoked []string, err error) {
+	p, err := rbacpolicies.List(n.Client, rbacpolicies.ListOpts{
+		ObjectType: objectType,
+		ObjectID:   objectID,
+		Action:     rbacpolicies.PolicyAction(action),
+	}).AllPages()
+	if err != nil {
+		return
+	}
+	current, err := rbacpolicies.ExtractRBACPolicies(p)
+	if err != nil {
+		return
+	}
+	granted := make(map[string]bool, len(current))
+	for _, c := range current {
+		granted[c.TargetTenant] = true
+	}
+	wanted := make(map[string]bool, len(targetIDs))
+	for _, id := range targetIDs {
+		wanted[id] = true
+		if granted[id] {
+			continue
+		}
+		granted[id] = true
+		opts := rbacpolicies.CreateOpts{
+			Action:       rbacpolicies.PolicyAction(action),
+			ObjectType:   objectType,
+			ObjectID:     objectID,
+			TargetTenant: id,
+		}
+		if _, err = rbacpolicies.Create(n.Client, opts).Extract(); err != nil {
+			return revoked, fmt.Errorf("cannot grant %s %s on %s %s: %w", id, action, objectType, objectID, err)
+		}
+	}
+	if !prune {
+		return
+	}
+	for _, c := range current {
+		if wanted[c.TargetTenant] {
+			continue
+		}
+		if c.TargetTenant == "*" {
+			implicit, err := n.implicitRBACPolicy(objectType, objectID, action)
+			if err != nil {
+				return revoked, err
+			}
+			if implicit {
+				continue
+			}
+		}
+		if err = rbacpolicies.Delete(n.Client, c.ID).ExtractErr(); err != nil {
+			return revoked, fmt.Errorf("cannot revoke %s %s on %s %s: %w", c.TargetTenant, action, objectType, objectID, err)
+		}
+		revoked = append(revoked, c.TargetTenant)
+	}
+	return
+}
+
+// implicitRBACPolicy reports whether neutron granted the action on the object to all projects (*),
+// because the object is shared or, for networks, external.
+func (n *Neutron) implicitRBACPolicy(objectType, objectID, action string) (bool, error) {
+	c, ok := rbacObjectCollections[objectType]
+	if !ok {
+		return false, fmt.Errorf("invalid object type %s", objectType)
+	}
+	// the response has a single key, the singular of the collection
+	var r map[string]struct {
+		Shared         bool `json:"shared"`
+		RouterExternal bool `json:"router:external"`
+	}
+	if _, err := n.Client.Get(n.Client.ServiceURL(c.path, objectID), &r, nil); err != nil {
+		return false, fmt.Errorf("cannot get %s %s: %w", objectType, objectID, err)
+	}
+	for _, object := range r {
+		switch rbacpolicies.PolicyAction(action) {
+		case rbacpolicies.ActionAccessShared:
+			return object.Shared, nil
+		case rbacpolicies.ActionAccessExternal:
+			return object.RouterExternal, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,299 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/routers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+)
+
+// Router is a neutron router including the attributes of the l3-ha, l3-flavors and service-type extensions.
+type Router struct {
+	routers.Router
+	HA            *bool  `json:"ha"`
+	FlavorID      string `json:"flavor_id"`
+	ServiceTypeID string `json:"service_type_id"`
+}
+
+// routerOpts supports the extension attributes of routers, unlike routers.CreateOpts.
+type routerOpts struct {
+	Name          string               `json:"name,omitempty"`
+	Description   *string              `json:"description,omitempty"`
+	ProjectID     string               `json:"project_id,omitempty"`
+	AdminStateUp  *bool                `json:"admin_state_up,omitempty"`
+	Distributed   *bool                `json:"distributed,omitempty"`
+	HA            *bool                `json:"ha,omitempty"`
+	FlavorID      string               `json:"flavor_id,omitempty"`
+	ServiceTypeID string               `json:"service_type_id,omitempty"`
+	GatewayInfo   *routers.GatewayInfo `json:"external_gateway_info,omitempty"`
+	Routes        *[]routers.Route     `json:"routes,omitempty"`
+}
+
+func (opts routerOpts) ToRouterCreateMap() (map[string]interface{}, error) {
+	return gophercloud.BuildRequestBody(opts, "router")
+}
+
+func (opts routerOpts) ToRouterUpdateMap() (map[string]interface{}, error) {
+	return gophercloud.BuildRequestBody(opts, "router")
+}
+
+// GetRouter returns the router of the project with the given name, or nil if it does not exist.
+func (n *Neutron) GetRouter(projectID, name string) (*Router, error) {
+	p, err := routers.List(n.Client, routers.ListOpts{Name: name, ProjectID: projectID}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	var r struct {
+		Routers []Router `json:"routers"`
+	}
+	if err = p.(routers.RouterPage).ExtractInto(&r); err != nil {
+		return nil, err
+	}
+	if len(r.Routers) == 0 {
+		return nil, nil
+	}
+	return &r.Routers[0], nil
+}
+
+// gatewayInfo resolves the external network and the subnets of the external fixed ips of a router gateway.
+// The external network usually belongs to another project, so plain names are looked up in all projects.
+func (n *Neutron) gatewayInfo(spec *openstackstablesapccv2.ExternalGatewayInfoSpec) (info *routers.GatewayInfo, err error) {
+	info = &routers.GatewayInfo{NetworkID: spec.NetworkId, EnableSNAT: spec.EnableSNAT}
+	if info.NetworkID == "" {
+		if spec.Network == "" {
+			return nil, fmt.Errorf("external gateway needs a network or network_id")
+		}
+		if info.NetworkID, err = n.GetNetworkID(spec.Network, ""); err != nil {
+			return
+		}
+	}
+	for _, ip := range spec.ExternalFixedIPs {
+		subnetID := ip.SubnetId
+		if subnetID == "" && ip.Subnet != "" {
+			if subnetID, err = n.GetSubnetID(ip.Subnet, ""); err != nil {
+				return
+			}
+		}
+		info.ExternalFixedIPs = append(info.ExternalFixedIPs, routers.ExternalFixedIP{SubnetID: subnetID, IPAddress: ip.IpAddress})
+	}
+	return
+}
+
+// gatewayEqual compares the declared gateway with the current one. SNAT and the external fixed ips are only
+// compared if they are declared. Declared fixed ips without address match any address of their subnet.
+func gatewayEqual(desired *routers.GatewayInfo, current routers.GatewayInfo) bool {
+	if desired.NetworkID != current.NetworkID {
+		return false
+	}
+	if desired.EnableSNAT != nil && (current.EnableSNAT == nil || *desired.EnableSNAT != *current.EnableSNAT) {
+		return false
+	}
+	if len(desired.ExternalFixedIPs) == 0 {
+		return true
+	}
+	if len(desired.ExternalFixedIPs) != len(current.ExternalFixedIPs) {
+		return false
+	}
+	used := make([]bool, len(current.ExternalFixedIPs))
+	for _, d := range desired.ExternalFixedIPs {
+		found := false
+		for i, c := range current.ExternalFixedIPs {
+			if !used[i] && d.SubnetID == c.SubnetID && (d.IPAddress == "" || normalizeIP(d.IPAddress) == normalizeIP(c.IPAddress)) {
+				used[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// routeKeys returns the sorted destination,nexthop keys of routes.
+func routeKeys(routes []routers.Route) []string {
+	keys := make([]string, len(routes))
+	for i, r := range routes {
+		keys[i] = r.DestinationCIDR + "," + normalizeIP(r.NextHop)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SeedRouter creates or updates a router of the project, its external gateway, interfaces and static routes.
+// Interfaces and routes are only managed if they are declared. Undeclared interfaces are removed then.
+// The distributed and ha mode, the flavor and the service type of a router cannot be changed in place,
+// so differences are returned as drift.
+func (n *Neutron) SeedRouter(projectID string, spec openstackstablesapccv2.RouterSpec) (updated *Router, drift []Drift, err error) {
+	var gateway *routers.GatewayInfo
+	if spec.ExternalGatewayInfo != nil {
+		if gateway, err = n.gatewayInfo(spec.ExternalGatewayInfo); err != nil {
+			return nil, nil, fmt.Errorf("router %s: %w", spec.Name, err)
+		}
+	}
+	var routes []routers.Route
+	for _, r := range spec.Routes {
+		routes = append(routes, routers.Route{DestinationCIDR: r.Destination, NextHop: r.Nexthop})
+	}
+
+	updated, err = n.GetRouter(projectID, spec.Name)
+	if err != nil {
+		return
+	}
+	if updated == nil {
+		opts := routerOpts{
+			Name:          spec.Name,
+			Description:   &spec.Description,
+			ProjectID:     projectID,
+			AdminStateUp:  spec.AdminStateUp,
+			Distributed:   spec.Distributed,
+			HA:            spec.HA,
+			FlavorID:      spec.FlavorId,
+			ServiceTypeID: spec.ServiceTypeId,
+			GatewayInfo:   gateway,
+		}
+		var r struct {
+			Router Router `json:"router"`
+		}
+		if err = routers.Create(n.Client, opts).ExtractInto(&r); err != nil {
+			return nil, nil, fmt.Errorf("cannot create router %s: %w", spec.Name, err)
+		}
+		updated = &r.Router
+	} else {
+		resource := fmt.Sprintf("router %s", spec.Name)
+		if spec.Distributed != nil && *spec.Distributed != updated.Distributed {
+			drift = append(drift, Drift{Resource: resource, Field: "distributed", Desired: *spec.Distributed, Actual: updated.Distributed})
+		}
+		if spec.HA != nil && (updated.HA == nil || *spec.HA != *updated.HA) {
+			drift = append(drift, Drift{Resource: resource, Field: "ha", Desired: *spec.HA, Actual: updated.HA != nil && *updated.HA})
+		}
+		if spec.FlavorId != "" && spec.FlavorId != updated.FlavorID {
+			drift = append(drift, Drift{Resource: resource, Field: "flavor_id", Desired: spec.FlavorId, Actual: updated.FlavorID})
+		}
+		if spec.ServiceTypeId != "" && spec.ServiceTypeId != updated.ServiceTypeID {
+			drift = append(drift, Drift{Resource: resource, Field: "service_type_id", Desired: spec.ServiceTypeId, Actual: updated.ServiceTypeID})
+		}
+
+		changed := false
+		opts := routerOpts{}
+		if updated.Description != spec.Description {
+			opts.Description, changed = &spec.Description, true
+		}
+		if spec.AdminStateUp != nil && *spec.AdminStateUp != updated.AdminStateUp {
+			opts.AdminStateUp, changed = spec.AdminStateUp, true
+		}
+		if gateway != nil && !gatewayEqual(gateway, updated.GatewayInfo) {
+			opts.GatewayInfo, changed = gateway, true
+		}
+		if changed {
+			var r struct {
+				Router Router `json:"router"`
+			}
+			if err = routers.Update(n.Client, updated.ID, opts).ExtractInto(&r); err != nil {
+				return updated, drift, fmt.Errorf("cannot update router %s: %w", spec.Name, err)
+			}
+			updated = &r.Router
+		}
+	}
+
+	if spec.RouterPorts != nil {
+		if err = n.seedRouterInterfaces(projectID, updated.ID, spec); err != nil {
+			return updated, drift, err
+		}
+	}
+	// routes can only be set after the interfaces of their nexthops exist
+	if spec.Routes != nil && !stringsEqual(routeKeys(routes), routeKeys(updated.Routes)) {
+		if routes == nil {
+			routes = []routers.Route{}
+		}
+		if _, err = routers.Update(n.Client, updated.ID, routerOpts{Routes: &routes}).Extract(); err != nil {
+			return updated, drift, fmt.Errorf("cannot set routes of router %s: %w", spec.Name, err)
+		}
+	}
+	return
+}
+
+// seedRouterInterfaces adds the declared interfaces to the router and removes all others.
+func (n *Neutron) seedRouterInterfaces(projectID, routerID string, spec openstackstablesapccv2.RouterSpec) (err error) {
+	p, err := ports.List(n.Client, ports.ListOpts{DeviceID: routerID}).AllPages()
+	if err != nil {
+		return
+	}
+	current, err := ports.ExtractPorts(p)
+	if err != nil {
+		return
+	}
+	wantedPorts := make(map[string]bool)
+	wantedSubnets := make(map[string]bool)
+	var opts []routers.AddInterfaceOpts
+	for _, rp := range spec.RouterPorts {
+		switch {
+		case rp.PortId != "":
+			wantedPorts[rp.PortId] = true
+			opts = append(opts, routers.AddInterfaceOpts{PortID: rp.PortId})
+		case rp.SubnetId != "" || rp.Subnet != "":
+			subnetID := rp.SubnetId
+			if subnetID == "" {
+				if subnetID, err = n.GetSubnetID(rp.Subnet, projectID); err != nil {
+					return fmt.Errorf("router %s: %w", spec.Name, err)
+				}
+			}
+			wantedSubnets[subnetID] = true
+			opts = append(opts, routers.AddInterfaceOpts{SubnetID: subnetID})
+		default:
+			return fmt.Errorf("router %s: interfaces need a port_id, subnet_id or subnet", spec.Name)
+		}
+	}
+
+	attachedPorts := make(map[string]bool)
+	attachedSubnets := make(map[string]bool)
+	for _, port := range current {
+		if !strings.HasPrefix(port.DeviceOwner, "network:router_interface") {
+			continue
+		}
+		wanted := wantedPorts[port.ID]
+		for _, ip := range port.FixedIPs {
+			wanted = wanted || wantedSubnets[ip.SubnetID]
+		}
+		if !wanted {
+			if _, err = routers.RemoveInterface(n.Client, routerID, routers.RemoveInterfaceOpts{PortID: port.ID}).Extract(); err != nil {
+				return fmt.Errorf("cannot remove interface %s from router %s: %w", port.ID, spec.Name, err)
+			}
+			continue
+		}
+		attachedPorts[port.ID] = true
+		for _, ip := range port.FixedIPs {
+			attachedSubnets[ip.SubnetID] = true
+		}
+	}
+	for _, o := range opts {
+		if attachedPorts[o.PortID] || attachedSubnets[o.SubnetID] {
+			continue
+		}
+		if _, err = routers.AddInterface(n.Client, routerID, o).Extract(); err != nil {
+			return fmt.Errorf("cannot add interface to router %s: %w", spec.Name, err)
+		}
+	}
+	return
+}
@@ -0,0 +1,240 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/groups"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+)
+
+// ValidateSecurityGroup checks the rules of a security group.
+func ValidateSecurityGroup(spec openstackstablesapccv2.SecurityGroupSpec) error {
+	for _, r := range spec.Rules {
+		if err := validateSecurityGroupRule(r); err != nil {
+			return fmt.Errorf("security group %s: %w", spec.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateSecurityGroupRule(r openstackstablesapccv2.SecurityGroupRuleSpec) error {
+	if r.Direction != "ingress" && r.Direction != "egress" {
+		return fmt.Errorf("invalid direction %s", r.Direction)
+	}
+	etherType := securityGroupRuleEtherType(r)
+	if etherType != "IPv4" && etherType != "IPv6" {
+		return fmt.Errorf("invalid ethertype %s", r.EtherType)
+	}
+	if r.RemoteIPPrefix != "" {
+		if r.RemoteGroup != "" {
+			return fmt.Errorf("rule cannot have both a remote_ip_prefix and a remote_group")
+		}
+		_, cidr, err := net.ParseCIDR(r.RemoteIPPrefix)
+		if err != nil {
+			return fmt.Errorf("invalid remote_ip_prefix %s", r.RemoteIPPrefix)
+		}
+		if (cidr.IP.To4() != nil) != (etherType == "IPv4") {
+			return fmt.Errorf("remote_ip_prefix %s does not match ethertype %s", r.RemoteIPPrefix, etherType)
+		}
+	}
+	if r.PortRangeMin != 0 || r.PortRangeMax != 0 {
+		if r.Protocol == "" {
+			return fmt.Errorf("port ranges need a protocol")
+		}
+		if r.PortRangeMin < 0 || r.PortRangeMax < 0 || r.PortRangeMax > 65535 {
+			return fmt.Errorf("invalid port range %d-%d", r.PortRangeMin, r.PortRangeMax)
+		}
+		// the port range of icmp rules holds the type and code
+		icmp := securityGroupRuleProtocol(etherType, r.Protocol)
+		if icmp != "1" && icmp != "58" && r.PortRangeMax != 0 && r.PortRangeMin > r.PortRangeMax {
+			return fmt.Errorf("port_range_min %d is larger than port_range_max %d", r.PortRangeMin, r.PortRangeMax)
+		}
+	}
+	return nil
+}
+
+// securityGroupRuleEtherType returns the ethertype of a rule, which defaults to IPv4.
+func securityGroupRuleEtherType(r openstackstablesapccv2.SecurityGroupRuleSpec) string {
+	if r.EtherType == "" {
+		return "IPv4"
+	}
+	return r.EtherType
+}
+
+// ipProtocolNumbers maps the ip protocol names neutron accepts to their numbers.
+var ipProtocolNumbers = map[string]int{
+	"ah": 51, "dccp": 33, "egp": 8, "esp": 50, "gre": 47, "icmp": 1, "icmpv6": 58, "igmp": 2, "ipip": 4,
+	"ipv6-encap": 41, "ipv6-frag": 44, "ipv6-icmp": 58, "ipv6-nonxt": 59, "ipv6-opts": 60, "ipv6-route": 43,
+	"ospf": 89, "pgm": 113, "rsvp": 46, "sctp": 132, "tcp": 6, "udp": 17, "udplite": 136, "vrrp": 112,
+}
+
+// portRangeProtocols are the protocols (by number) whose port_range_max neutron sets to port_range_min,
+// if only the latter is given.
+var portRangeProtocols = map[string]bool{"6": true, "17": true, "33": true, "132": true, "136": true}
+
+// securityGroupRuleProtocol returns the number of the protocol of a rule, so that names and numbers
+// of the same protocol are equal. Unknown protocols are returned in lower case.
+func securityGroupRuleProtocol(etherType, protocol string) string {
+	p := strings.ToLower(protocol)
+	if n, ok := ipProtocolNumbers[p]; ok {
+		p = strconv.Itoa(n)
+	} else if n, err := strconv.Atoi(p); err == nil {
+		p = strconv.Itoa(n)
+	}
+	// neutron takes icmp in IPv6 rules for ipv6-icmp
+	if p == "1" && etherType == "IPv6" {
+		p = "58"
+	}
+	return p
+}
+
+// securityGroupRuleKey identifies a rule by all its attributes except the description, which cannot be updated.
+// Protocols and port ranges are compared the way neutron stores them, and remote prefixes matching all addresses
+// are equivalent to no remote prefix.
+func securityGroupRuleKey(direction, etherType, protocol string, min, max int, remoteIPPrefix, remoteGroupID string) string {
+	protocol = securityGroupRuleProtocol(etherType, protocol)
+	if max == 0 && portRangeProtocols[protocol] {
+		max = min
+	}
+	if _, cidr, err := net.ParseCIDR(remoteIPPrefix); err == nil {
+		remoteIPPrefix = cidr.String()
+		if ones, _ := cidr.Mask.Size(); ones == 0 {
+			remoteIPPrefix = ""
+		}
+	}
+	return fmt.Sprintf("%s/%s/%s/%d-%d/%s/%s", direction, etherType, protocol, min, max, remoteIPPrefix, remoteGroupID)
+}
+
+// GetSecurityGroup returns the security group of the project with the given name including its rules,
+// or nil if it does not exist.
+func (n *Neutron) GetSecurityGroup(projectID, name string) (*groups.SecGroup, error) {
+	p, err := groups.List(n.Client, groups.ListOpts{Name: name, ProjectID: projectID}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	r, err := groups.ExtractGroups(p)
+	if err != nil || len(r) == 0 {
+		return nil, err
+	}
+	return &r[0], nil
+}
+
+// SeedSecurityGroups creates or updates the security groups of the project. The rules are seeded after
+// all security groups exist, because rules may reference other security groups of the project.
+func (n *Neutron) SeedSecurityGroups(projectID string, specs []openstackstablesapccv2.SecurityGroupSpec, prune bool) (err error) {
+	seeded := make([]*groups.SecGroup, len(specs))
+	for i, spec := range specs {
+		if seeded[i], err = n.seedSecurityGroup(projectID, spec); err != nil {
+			return
+		}
+	}
+	for i, spec := range specs {
+		if err = n.seedSecurityGroupRules(projectID, seeded[i], spec, prune); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (n *Neutron) seedSecurityGroup(projectID string, spec openstackstablesapccv2.SecurityGroupSpec) (group *groups.SecGroup, err error) {
+	group, err = n.GetSecurityGroup(projectID, spec.Name)
+	if err != nil {
+		return
+	}
+	if group == nil {
+		opts := groups.CreateOpts{Name: spec.Name, Description: spec.Description, ProjectID: projectID}
+		if group, err = groups.Create(n.Client, opts).Extract(); err != nil {
+			return nil, fmt.Errorf("cannot create security group %s: %w", spec.Name, err)
+		}
+	} else if group.Description != spec.Description {
+		if group, err = groups.Update(n.Client, group.ID, groups.UpdateOpts{Description: &spec.Description}).Extract(); err != nil {
+			return nil, fmt.Errorf("cannot update security group %s: %w", spec.Name, err)
+		}
+	}
+	return
+}
+
+// seedSecurityGroupRules adds the declared rules which the security group lacks. Rules are compared as sets of
+// their attributes. If prune is set, all other rules of the security group are deleted, including the egress rules
+// neutron adds to new security groups.
+func (n *Neutron) seedSecurityGroupRules(projectID string, group *groups.SecGroup, spec openstackstablesapccv2.SecurityGroupSpec, prune bool) (err error) {
+	keys := make([]string, len(group.Rules))
+	current := make(map[string]bool, len(group.Rules))
+	for i, r := range group.Rules {
+		keys[i] = securityGroupRuleKey(r.Direction, r.EtherType, r.Protocol, r.PortRangeMin, r.PortRangeMax, r.RemoteIPPrefix, r.RemoteGroupID)
+		current[keys[i]] = true
+	}
+	wanted := make(map[string]bool, len(spec.Rules))
+	for _, r := range spec.Rules {
+		remoteGroupID := ""
+		if r.RemoteGroup != "" {
+			remote := group
+			if r.RemoteGroup != spec.Name {
+				if remote, err = n.GetSecurityGroup(projectID, r.RemoteGroup); err != nil {
+					return
+				}
+				if remote == nil {
+					return fmt.Errorf("security group %s: could not find remote security group: %s", spec.Name, r.RemoteGroup)
+				}
+			}
+			remoteGroupID = remote.ID
+		}
+		etherType := securityGroupRuleEtherType(r)
+		key := securityGroupRuleKey(r.Direction, etherType, r.Protocol, r.PortRangeMin, r.PortRangeMax, r.RemoteIPPrefix, remoteGroupID)
+		if wanted[key] {
+			continue
+		}
+		wanted[key] = true
+		if current[key] {
+			continue
+		}
+		opts := rules.CreateOpts{
+			Direction:      rules.RuleDirection(r.Direction),
+			EtherType:      rules.RuleEtherType(etherType),
+			SecGroupID:     group.ID,
+			Protocol:       rules.RuleProtocol(r.Protocol),
+			PortRangeMin:   r.PortRangeMin,
+			PortRangeMax:   r.PortRangeMax,
+			RemoteIPPrefix: r.RemoteIPPrefix,
+			RemoteGroupID:  remoteGroupID,
+			Description:    r.Description,
+			ProjectID:      projectID,
+		}
+		if _, err = rules.Create(n.Client, opts).Extract(); err != nil {
+			return fmt.Errorf("cannot add rule %s to security group %s: %w", key, spec.Name, err)
+		}
+	}
+	if !prune {
+		return
+	}
+	for i, r := range group.Rules {
+		if wanted[keys[i]] {
+			continue
+		}
+		if err = rules.Delete(n.Client, r.ID).ExtractErr(); err != nil {
+			return fmt.Errorf("cannot delete rule %s of security group %s: %w", keys[i], spec.Name, err)
+		}
+	}
+	return
+}
@@ -0,0 +1,190 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gophercloud/gophercloud"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+)
+
+// maxSegmentationIDs are the largest segmentation ids of the network types supporting segment ranges.
+var maxSegmentationIDs = map[string]int64{
+	"vlan":   4094,
+	"vxlan":  1<<24 - 1,
+	"geneve": 1<<24 - 1,
+	"gre":    1<<32 - 1,
+}
+
+// NetworkSegmentRange is a neutron network segment range. gophercloud does not support the
+// network-segment-range extension.
+type NetworkSegmentRange struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	Default         bool   `json:"default"`
+	Shared          bool   `json:"shared"`
+	ProjectID       string `json:"project_id"`
+	NetworkType     string `json:"network_type"`
+	PhysicalNetwork string `json:"physical_network"`
+	Minimum         int    `json:"minimum"`
+	Maximum         int    `json:"maximum"`
+}
+
+// networkSegmentRangeOpts are the attributes of a network segment range to create or update.
+// Only the name, description, minimum and maximum of a range can be updated.
+type networkSegmentRangeOpts struct {
+	Name            string  `json:"name,omitempty"`
+	Description     *string `json:"description,omitempty"`
+	Shared          *bool   `json:"shared,omitempty"`
+	ProjectID       string  `json:"project_id,omitempty"`
+	NetworkType     string  `json:"network_type,omitempty"`
+	PhysicalNetwork string  `json:"physical_network,omitempty"`
+	Minimum         int     `json:"minimum,omitempty"`
+	Maximum         int     `json:"maximum,omitempty"`
+}
+
+// ValidateNetworkSegmentRange checks the network type, physical network, bounds and project of a network segment range.
+func ValidateNetworkSegmentRange(spec openstackstablesapccv2.NetworkSegmentRangeSpec) error {
+	if spec.Name == "" {
+		return fmt.Errorf("network segment range of %s: no name", spec.NetworkType)
+	}
+	max, ok := maxSegmentationIDs[spec.NetworkType]
+	if !ok {
+		return fmt.Errorf("network segment range %s: invalid network_type %s", spec.Name, spec.NetworkType)
+	}
+	if (spec.NetworkType == "vlan") != (spec.PhysicalNetwork != "") {
+		return fmt.Errorf("network segment range %s: physical_network is required for vlan ranges and not supported for others", spec.Name)
+	}
+	if spec.Minimum < 1 || int64(spec.Maximum) > max || spec.Minimum > spec.Maximum {
+		return fmt.Errorf("network segment range %s: invalid range %d-%d: must be within 1-%d", spec.Name, spec.Minimum, spec.Maximum, max)
+	}
+	if spec.Project != "" && len(strings.Split(spec.Project, "@")) != 2 {
+		return fmt.Errorf("network segment range %s: invalid project %s: must be project@domain", spec.Name, spec.Project)
+	}
+	return nil
+}
+
+// ValidateSegmentationID checks that the provider segmentation id of a network of the project (project@domain)
+// lies in one of the network segment ranges of its network type and physical network the project can use.
+// Networks of network types and physical networks without declared ranges are not checked.
+func ValidateSegmentationID(spec openstackstablesapccv2.NetworkSpec, project string, ranges []openstackstablesapccv2.NetworkSegmentRangeSpec) error {
+	if spec.ProviderSegmentationId == "" {
+		return nil
+	}
+	id, err := strconv.Atoi(spec.ProviderSegmentationId)
+	if err != nil {
+		return fmt.Errorf("network %s: invalid provider_segmentation_id %s", spec.Name, spec.ProviderSegmentationId)
+	}
+	declared := false
+	for _, r := range ranges {
+		if r.NetworkType != spec.ProviderNetworkType || r.PhysicalNetwork != spec.ProviderPhysicalNetwork {
+			continue
+		}
+		declared = true
+		if (r.Project == "" || r.Project == project) && id >= r.Minimum && id <= r.Maximum {
+			return nil
+		}
+	}
+	if !declared {
+		return nil
+	}
+	return fmt.Errorf("network %s: provider_segmentation_id %d is not in a network segment range of %s %s available to project %s",
+		spec.Name, id, spec.ProviderNetworkType, spec.ProviderPhysicalNetwork, project)
+}
+
+// GetNetworkSegmentRange returns the network segment range with the given name, or nil if it does not exist.
+func (n *Neutron) GetNetworkSegmentRange(name string) (*NetworkSegmentRange, error) {
+	var r struct {
+		NetworkSegmentRanges []NetworkSegmentRange `json:"network_segment_ranges"`
+	}
+	query := url.Values{"name": {name}}
+	if _, err := n.Client.Get(n.Client.ServiceURL("network_segment_ranges")+"?"+query.Encode(), &r, nil); err != nil {
+		return nil, err
+	}
+	if len(r.NetworkSegmentRanges) == 0 {
+		return nil, nil
+	}
+	return &r.NetworkSegmentRanges[0], nil
+}
+
+// SeedNetworkSegmentRange creates or updates a network segment range. The network type, physical network
+// and project of a range cannot be changed, so differences are returned as drift.
+func (n *Neutron) SeedNetworkSegmentRange(spec openstackstablesapccv2.NetworkSegmentRangeSpec) (drift []Drift, err error) {
+	projectID := ""
+	if spec.Project != "" {
+		parts := strings.Split(spec.Project, "@")
+		if projectID, err = n.Keystone.GetProjectID(parts[1], parts[0]); err != nil {
+			return
+		}
+	}
+	current, err := n.GetNetworkSegmentRange(spec.Name)
+	if err != nil {
+		return
+	}
+	var r struct {
+		NetworkSegmentRange NetworkSegmentRange `json:"network_segment_range"`
+	}
+	if current == nil {
+		shared := projectID == ""
+		body, _ := gophercloud.BuildRequestBody(networkSegmentRangeOpts{
+			Name:            spec.Name,
+			Description:     &spec.Description,
+			Shared:          &shared,
+			ProjectID:       projectID,
+			NetworkType:     spec.NetworkType,
+			PhysicalNetwork: spec.PhysicalNetwork,
+			Minimum:         spec.Minimum,
+			Maximum:         spec.Maximum,
+		}, "network_segment_range")
+		if _, err = n.Client.Post(n.Client.ServiceURL("network_segment_ranges"), body, &r, nil); err != nil {
+			return nil, fmt.Errorf("cannot create network segment range %s: %w", spec.Name, err)
+		}
+		return
+	}
+
+	resource := fmt.Sprintf("network segment range %s", spec.Name)
+	if spec.NetworkType != current.NetworkType {
+		drift = append(drift, Drift{Resource: resource, Field: "network_type", Desired: spec.NetworkType, Actual: current.NetworkType})
+	}
+	if spec.PhysicalNetwork != current.PhysicalNetwork {
+		drift = append(drift, Drift{Resource: resource, Field: "physical_network", Desired: spec.PhysicalNetwork, Actual: current.PhysicalNetwork})
+	}
+	if projectID != current.ProjectID || (projectID == "") != current.Shared {
+		drift = append(drift, Drift{Resource: resource, Field: "project", Desired: spec.Project, Actual: current.ProjectID})
+	}
+	changed := false
+	opts := networkSegmentRangeOpts{}
+	if spec.Description != current.Description {
+		opts.Description, changed = &spec.Description, true
+	}
+	if spec.Minimum != current.Minimum || spec.Maximum != current.Maximum {
+		opts.Minimum, opts.Maximum, changed = spec.Minimum, spec.Maximum, true
+	}
+	if changed {
+		body, _ := gophercloud.BuildRequestBody(opts, "network_segment_range")
+		if _, err = n.Client.Put(n.Client.ServiceURL("network_segment_ranges", current.ID), body, &r, &gophercloud.RequestOpts{OkCodes: []int{200}}); err != nil {
+			return drift, fmt.Errorf("cannot update network segment range %s: %w", spec.Name, err)
+		}
+	}
+	return
+}
@@ -0,0 +1,145 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+)
+
+// ValidateSubnet checks the cidr, gateway, allocation pools and host routes of a subnet.
+// Allocation pools, gateway and the nexthops of host routes have to lie inside the cidr, if the cidr is declared.
+func ValidateSubnet(spec openstackstablesapccv2.SubnetSpec) (err error) {
+	var cidr *net.IPNet
+	if spec.CIDR != "" {
+		if _, cidr, err = net.ParseCIDR(spec.CIDR); err != nil {
+			return fmt.Errorf("subnet %s: invalid cidr %s", spec.Name, spec.CIDR)
+		}
+	}
+	if spec.GatewayIP != "" {
+		if err = checkIP(spec.GatewayIP, cidr); err != nil {
+			return fmt.Errorf("subnet %s: invalid gateway_ip: %w", spec.Name, err)
+		}
+	}
+	if _, err = parseAllocationPools(spec.AllocationPools, cidr); err != nil {
+		return fmt.Errorf("subnet %s: %w", spec.Name, err)
+	}
+	if _, err = parseHostRoutes(spec.HostRoutes, cidr); err != nil {
+		return fmt.Errorf("subnet %s: %w", spec.Name, err)
+	}
+	return
+}
+
+// checkIP checks that ip is an ip address inside cidr (if not nil).
+func checkIP(ip string, cidr *net.IPNet) error {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return fmt.Errorf("%s is not an ip address", ip)
+	}
+	if cidr != nil && !cidr.Contains(parsed) {
+		return fmt.Errorf("%s is not inside %s", ip, cidr)
+	}
+	return nil
+}
+
+// normalizeIP formats an ip address like neutron does, e.g. IPv6 addresses in their shortest form.
+func normalizeIP(ip string) string {
+	if parsed := net.ParseIP(ip); parsed != nil {
+		return parsed.String()
+	}
+	return ip
+}
+
+// parseAllocationPools parses allocation pools of the form start-end. If cidr is not nil,
+// both addresses have to lie inside of it.
+func parseAllocationPools(pools []string, cidr *net.IPNet) (r []subnets.AllocationPool, err error) {
+	r = make([]subnets.AllocationPool, 0, len(pools))
+	for _, p := range pools {
+		ips := strings.Split(p, "-")
+		if len(ips) != 2 {
+			return nil, fmt.Errorf("invalid allocation pool %s: must be start-end", p)
+		}
+		start, end := strings.TrimSpace(ips[0]), strings.TrimSpace(ips[1])
+		for _, ip := range []string{start, end} {
+			if err = checkIP(ip, cidr); err != nil {
+				return nil, fmt.Errorf("invalid allocation pool %s: %w", p, err)
+			}
+		}
+		if bytes.Compare(net.ParseIP(start).To16(), net.ParseIP(end).To16()) > 0 {
+			return nil, fmt.Errorf("invalid allocation pool %s: start is after end", p)
+		}
+		r = append(r, subnets.AllocationPool{Start: normalizeIP(start), End: normalizeIP(end)})
+	}
+	return
+}
+
+// parseHostRoutes parses host routes of the form destination,nexthop. If cidr is not nil,
+// the nexthop has to lie inside of it.
+func parseHostRoutes(routes []string, cidr *net.IPNet) (r []subnets.HostRoute, err error) {
+	r = make([]subnets.HostRoute, 0, len(routes))
+	for _, route := range routes {
+		parts := strings.Split(route, ",")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid host route %s: must be destination,nexthop", route)
+		}
+		destination, nexthop := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		_, dst, err := net.ParseCIDR(destination)
+		if err != nil {
+			return nil, fmt.Errorf("invalid host route %s: %s is not a cidr", route, destination)
+		}
+		if err = checkIP(nexthop, cidr); err != nil {
+			return nil, fmt.Errorf("invalid host route %s: %w", route, err)
+		}
+		r = append(r, subnets.HostRoute{DestinationCIDR: dst.String(), NextHop: normalizeIP(nexthop)})
+	}
+	return
+}
+
+// allocationPoolsEqual compares allocation pools independent of their order.
+func allocationPoolsEqual(a, b []subnets.AllocationPool) bool {
+	return stringsEqual(allocationPoolKeys(a), allocationPoolKeys(b))
+}
+
+func allocationPoolKeys(pools []subnets.AllocationPool) []string {
+	keys := make([]string, len(pools))
+	for i, p := range pools {
+		keys[i] = normalizeIP(p.Start) + "-" + normalizeIP(p.End)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hostRoutesEqual compares host routes independent of their order.
+func hostRoutesEqual(a, b []subnets.HostRoute) bool {
+	return stringsEqual(hostRouteKeys(a), hostRouteKeys(b))
+}
+
+func hostRouteKeys(routes []subnets.HostRoute) []string {
+	keys := make([]string, len(routes))
+	for i, r := range routes {
+		keys[i] = r.DestinationCIDR + "," + normalizeIP(r.NextHop)
+	}
+	sort.Strings(keys)
+	return keys
+}
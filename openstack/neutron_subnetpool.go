@@ -0,0 +1,269 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/addressscopes"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/subnetpools"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+)
+
+// ValidateAddressScope checks the ip version of an address scope and its subnet pools.
+func ValidateAddressScope(spec openstackstablesapccv2.AddressScopeSpec) error {
+	if spec.IpVersion != 4 && spec.IpVersion != 6 {
+		return fmt.Errorf("address scope %s: invalid ip_version %d", spec.Name, spec.IpVersion)
+	}
+	for _, pool := range spec.SubnetPools {
+		if pool.AddressScopeId != "" {
+			return fmt.Errorf("address scope %s: subnet pool %s cannot have an address_scope_id", spec.Name, pool.Name)
+		}
+		version, err := subnetPoolIPVersion(pool)
+		if err != nil {
+			return err
+		}
+		if version != spec.IpVersion {
+			return fmt.Errorf("address scope %s: subnet pool %s has prefixes of ip version %d", spec.Name, pool.Name, version)
+		}
+	}
+	return nil
+}
+
+// ValidateSubnetPool checks the prefixes and prefix lengths of a subnet pool.
+func ValidateSubnetPool(spec openstackstablesapccv2.SubnetPoolSpec) error {
+	_, err := subnetPoolIPVersion(spec)
+	return err
+}
+
+// subnetPoolIPVersion returns the ip version of the prefixes of a subnet pool.
+func subnetPoolIPVersion(spec openstackstablesapccv2.SubnetPoolSpec) (version int, err error) {
+	if len(spec.Prefixes) == 0 {
+		return 0, fmt.Errorf("subnet pool %s: no prefixes", spec.Name)
+	}
+	prefixes, err := parsePrefixes(spec.Prefixes)
+	if err != nil {
+		return 0, fmt.Errorf("subnet pool %s: %w", spec.Name, err)
+	}
+	bits := 0
+	for _, p := range prefixes {
+		_, b := p.Mask.Size()
+		if bits != 0 && b != bits {
+			return 0, fmt.Errorf("subnet pool %s: prefixes of different ip versions", spec.Name)
+		}
+		bits = b
+	}
+	if spec.MinPrefixLen > bits || spec.DefaultPrefixLen > bits || spec.MaxPrefixLen > bits {
+		return 0, fmt.Errorf("subnet pool %s: prefix lengths must not exceed %d", spec.Name, bits)
+	}
+	if spec.MinPrefixLen != 0 && spec.MaxPrefixLen != 0 && spec.MinPrefixLen > spec.MaxPrefixLen {
+		return 0, fmt.Errorf("subnet pool %s: min_prefixlen %d is larger than max_prefixlen %d", spec.Name, spec.MinPrefixLen, spec.MaxPrefixLen)
+	}
+	if spec.DefaultPrefixLen != 0 && (spec.DefaultPrefixLen < spec.MinPrefixLen || spec.MaxPrefixLen != 0 && spec.DefaultPrefixLen > spec.MaxPrefixLen) {
+		return 0, fmt.Errorf("subnet pool %s: default_prefixlen %d is not between min_prefixlen and max_prefixlen", spec.Name, spec.DefaultPrefixLen)
+	}
+	if bits == 32 {
+		return 4, nil
+	}
+	return 6, nil
+}
+
+func parsePrefixes(prefixes []string) (r []*net.IPNet, err error) {
+	for _, prefix := range prefixes {
+		_, cidr, err := net.ParseCIDR(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prefix %s", prefix)
+		}
+		r = append(r, cidr)
+	}
+	return
+}
+
+// prefixCovered checks whether the prefix is covered by the union of the given prefixes.
+// Neutron merges adjacent prefixes of a subnet pool, so a prefix may be covered by several smaller ones.
+func prefixCovered(prefix *net.IPNet, prefixes []*net.IPNet) bool {
+	ones, bits := prefix.Mask.Size()
+	split := false
+	for _, p := range prefixes {
+		o, b := p.Mask.Size()
+		if b != bits {
+			continue
+		}
+		if o <= ones && p.Contains(prefix.IP) {
+			return true
+		}
+		if o > ones && prefix.Contains(p.IP) {
+			split = true
+		}
+	}
+	if !split {
+		return false
+	}
+	// check both halves of the prefix
+	mask := net.CIDRMask(ones+1, bits)
+	lower := &net.IPNet{IP: prefix.IP.Mask(mask), Mask: mask}
+	upper := &net.IPNet{IP: make(net.IP, len(lower.IP)), Mask: mask}
+	copy(upper.IP, lower.IP)
+	upper.IP[ones/8] |= 0x80 >> uint(ones%8)
+	return prefixCovered(lower, prefixes) && prefixCovered(upper, prefixes)
+}
+
+// GetAddressScope returns the address scope of the project with the given name, or nil if it does not exist.
+func (n *Neutron) GetAddressScope(projectID, name string) (*addressscopes.AddressScope, error) {
+	p, err := addressscopes.List(n.Client, addressscopes.ListOpts{Name: name, ProjectID: projectID}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	r, err := addressscopes.ExtractAddressScopes(p)
+	if err != nil || len(r) == 0 {
+		return nil, err
+	}
+	return &r[0], nil
+}
+
+// SeedAddressScope creates or updates an address scope of the project and seeds its subnet pools,
+// which are bound to the address scope. The ip version of an address scope cannot be changed,
+// so a difference is returned as drift.
+func (n *Neutron) SeedAddressScope(projectID string, spec openstackstablesapccv2.AddressScopeSpec) (id string, drift []Drift, err error) {
+	scope, err := n.GetAddressScope(projectID, spec.Name)
+	if err != nil {
+		return
+	}
+	if scope == nil {
+		opts := addressscopes.CreateOpts{
+			Name:      spec.Name,
+			ProjectID: projectID,
+			IPVersion: spec.IpVersion,
+			Shared:    spec.Shared != nil && *spec.Shared,
+		}
+		if scope, err = addressscopes.Create(n.Client, opts).Extract(); err != nil {
+			return "", nil, fmt.Errorf("cannot create address scope %s: %w", spec.Name, err)
+		}
+	} else {
+		if spec.IpVersion != scope.IPVersion {
+			drift = append(drift, Drift{Resource: fmt.Sprintf("address scope %s", spec.Name), Field: "ip_version", Desired: spec.IpVersion, Actual: scope.IPVersion})
+		}
+		if spec.Shared != nil && *spec.Shared != scope.Shared {
+			if _, err = addressscopes.Update(n.Client, scope.ID, addressscopes.UpdateOpts{Shared: spec.Shared}).Extract(); err != nil {
+				return scope.ID, drift, fmt.Errorf("cannot update address scope %s: %w", spec.Name, err)
+			}
+		}
+	}
+
+	for _, pool := range spec.SubnetPools {
+		pool.AddressScopeId = scope.ID
+		d, err := n.SeedSubnetPool(projectID, pool)
+		drift = append(drift, d...)
+		if err != nil {
+			return scope.ID, drift, err
+		}
+	}
+	return scope.ID, drift, nil
+}
+
+// GetSubnetPool returns the subnet pool of the project with the given name, or nil if it does not exist.
+func (n *Neutron) GetSubnetPool(projectID, name string) (*subnetpools.SubnetPool, error) {
+	p, err := subnetpools.List(n.Client, subnetpools.ListOpts{Name: name, ProjectID: projectID}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	r, err := subnetpools.ExtractSubnetPools(p)
+	if err != nil || len(r) == 0 {
+		return nil, err
+	}
+	return &r[0], nil
+}
+
+// SeedSubnetPool creates or updates a subnet pool of the project. Neutron only allows the prefixes of a subnet
+// pool to grow, so an error is returned if a current prefix is not covered by the declared ones.
+// Whether a subnet pool is shared cannot be changed, so a difference is returned as drift.
+func (n *Neutron) SeedSubnetPool(projectID string, spec openstackstablesapccv2.SubnetPoolSpec) (drift []Drift, err error) {
+	pool, err := n.GetSubnetPool(projectID, spec.Name)
+	if err != nil {
+		return
+	}
+	if pool == nil {
+		opts := subnetpools.CreateOpts{
+			Name:             spec.Name,
+			Description:      spec.Description,
+			ProjectID:        projectID,
+			Prefixes:         spec.Prefixes,
+			DefaultQuota:     spec.DefaultQuota,
+			DefaultPrefixLen: spec.DefaultPrefixLen,
+			MinPrefixLen:     spec.MinPrefixLen,
+			MaxPrefixLen:     spec.MaxPrefixLen,
+			AddressScopeID:   spec.AddressScopeId,
+			Shared:           spec.Shared != nil && *spec.Shared,
+			IsDefault:        spec.IsDefault != nil && *spec.IsDefault,
+		}
+		if _, err = subnetpools.Create(n.Client, opts).Extract(); err != nil {
+			return nil, fmt.Errorf("cannot create subnet pool %s: %w", spec.Name, err)
+		}
+		return
+	}
+
+	if spec.Shared != nil && *spec.Shared != pool.Shared {
+		drift = append(drift, Drift{Resource: fmt.Sprintf("subnet pool %s", spec.Name), Field: "shared", Desired: *spec.Shared, Actual: pool.Shared})
+	}
+	desired, _ := parsePrefixes(spec.Prefixes)
+	current, err := parsePrefixes(pool.Prefixes)
+	if err != nil {
+		return drift, fmt.Errorf("subnet pool %s: %w", spec.Name, err)
+	}
+	changed := false
+	opts := subnetpools.UpdateOpts{}
+	for _, p := range current {
+		if !prefixCovered(p, desired) {
+			return drift, fmt.Errorf("cannot remove prefix %s from subnet pool %s: subnet pool prefixes can only grow", p, spec.Name)
+		}
+	}
+	for _, p := range desired {
+		if !prefixCovered(p, current) {
+			opts.Prefixes, changed = spec.Prefixes, true
+			break
+		}
+	}
+	if spec.Description != pool.Description {
+		opts.Description, changed = &spec.Description, true
+	}
+	if spec.DefaultQuota != 0 && spec.DefaultQuota != pool.DefaultQuota {
+		opts.DefaultQuota, changed = &spec.DefaultQuota, true
+	}
+	if spec.MinPrefixLen != 0 && spec.MinPrefixLen != pool.MinPrefixLen {
+		opts.MinPrefixLen, changed = spec.MinPrefixLen, true
+	}
+	if spec.DefaultPrefixLen != 0 && spec.DefaultPrefixLen != pool.DefaultPrefixLen {
+		opts.DefaultPrefixLen, changed = spec.DefaultPrefixLen, true
+	}
+	if spec.MaxPrefixLen != 0 && spec.MaxPrefixLen != pool.MaxPrefixLen {
+		opts.MaxPrefixLen, changed = spec.MaxPrefixLen, true
+	}
+	if spec.AddressScopeId != "" && spec.AddressScopeId != pool.AddressScopeID {
+		opts.AddressScopeID, changed = &spec.AddressScopeId, true
+	}
+	if spec.IsDefault != nil && *spec.IsDefault != pool.IsDefault {
+		opts.IsDefault, changed = spec.IsDefault, true
+	}
+	if changed {
+		if _, err = subnetpools.Update(n.Client, pool.ID, opts).Extract(); err != nil {
+			return drift, fmt.Errorf("cannot update subnet pool %s: %w", spec.Name, err)
+		}
+	}
+	return
+}
@@ -0,0 +1,384 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/aggregates"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/quotasets"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/flavors"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+	"github.com/sapcc/openstack-seeder/pkg/cache"
+)
+
+type Nova struct {
+	Client *gophercloud.ServiceClient
+	cache  *cache.Cache
+	// RecreateFlavors deletes and recreates flavors whose immutable attributes differ from the spec.
+	RecreateFlavors bool
+}
+
+func NewNova(client *gophercloud.ServiceClient) (n *Nova) {
+	return &Nova{
+		cache:  cache.New(time.Until(time.Now().AddDate(0, 0, 7)), 30*time.Minute),
+		Client: client,
+	}
+}
+
+func NewComputeClient() (client *gophercloud.ServiceClient, err error) {
+	provider, err := newProviderClient()
+	if err != nil {
+		return
+	}
+	client, err = openstack.NewComputeV2(provider, regionEndpointOpts())
+	return
+}
+
+// SeedFlavor creates the flavor if it does not exist and seeds its extra specs.
+// Flavors cannot be updated, so differing attributes of an existing flavor are returned as drift,
+// unless RecreateFlavors is set.
+func (n *Nova) SeedFlavor(spec openstackstablesapccv2.FlavorSpec) (updated *flavors.Flavor, drift []Drift, err error) {
+	updated, disabled, err := n.getFlavor(spec)
+	if err != nil {
+		return
+	}
+	if updated == nil {
+		if updated, err = n.createFlavor(spec, spec.Id); err != nil {
+			return
+		}
+	} else {
+		drift = flavorDrift(spec, updated, disabled)
+		// createFlavor cannot set OS-FLV-DISABLED, so a drift of disabled alone is only reported
+		remaining := flavorDriftFields(drift, "disabled")
+		if len(remaining) < len(drift) && n.RecreateFlavors {
+			// the access list is lost with the flavor, so it is granted again to the recreated one
+			var granted []string
+			if !updated.IsPublic {
+				if granted, err = n.flavorAccess(updated.ID); err != nil {
+					return nil, drift, err
+				}
+			}
+			if err = flavors.Delete(n.Client, updated.ID).ExtractErr(); err != nil {
+				return nil, drift, fmt.Errorf("cannot delete flavor %s: %w", spec.Name, err)
+			}
+			if updated, err = n.createFlavor(spec, updated.ID); err != nil {
+				return nil, drift, err
+			}
+			drift = remaining
+			for _, id := range granted {
+				if _, err = flavors.AddAccess(n.Client, updated.ID, flavors.AddAccessOpts{Tenant: id}).Extract(); err != nil {
+					return updated, drift, fmt.Errorf("cannot grant project %s access to flavor %s: %w", id, updated.ID, err)
+				}
+			}
+		}
+	}
+	err = n.seedFlavorExtraSpecs(updated.ID, spec.ExtraSpecs)
+	return
+}
+
+// getFlavor looks up a flavor by id or, if the spec has none, by name.
+// It returns nil if the flavor does not exist.
+func (n *Nova) getFlavor(spec openstackstablesapccv2.FlavorSpec) (flavor *flavors.Flavor, disabled bool, err error) {
+	id := spec.Id
+	if id == "" {
+		p, err := flavors.ListDetail(n.Client, flavors.ListOpts{AccessType: flavors.AllAccess}).AllPages()
+		if err != nil {
+			return nil, false, err
+		}
+		fl, err := flavors.ExtractFlavors(p)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, f := range fl {
+			if f.Name == spec.Name {
+				id = f.ID
+				break
+			}
+		}
+		if id == "" {
+			return nil, false, nil
+		}
+	}
+	r := flavors.Get(n.Client, id)
+	if flavor, err = r.Extract(); err != nil {
+		if _, ok := err.(gophercloud.ErrDefault404); ok {
+			return nil, false, nil
+		}
+		return
+	}
+	var s struct {
+		Flavor struct {
+			Disabled bool `json:"OS-FLV-DISABLED:disabled"`
+		} `json:"flavor"`
+	}
+	err = r.ExtractInto(&s)
+	return flavor, s.Flavor.Disabled, err
+}
+
+func (n *Nova) createFlavor(spec openstackstablesapccv2.FlavorSpec, id string) (*flavors.Flavor, error) {
+	disk := spec.Disk
+	opts := flavors.CreateOpts{
+		ID:         id,
+		Name:       spec.Name,
+		RAM:        spec.Ram,
+		VCPUs:      spec.Vcpus,
+		Disk:       &disk,
+		Swap:       &spec.Swap,
+		Ephemeral:  &spec.Ephemeral,
+		RxTxFactor: float64(spec.RxTxfactor),
+		IsPublic:   spec.IsPublic,
+	}
+	f, err := flavors.Create(n.Client, opts).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("cannot create flavor %s: %w", spec.Name, err)
+	}
+	return f, nil
+}
+
+// flavorDrift compares the immutable attributes of an existing flavor with its spec.
+// flavorDriftFields returns the drift of the given fields.
+func flavorDriftFields(drift []Drift, fields ...string) (filtered []Drift) {
+	for _, d := range drift {
+		for _, f := range fields {
+			if d.Field == f {
+				filtered = append(filtered, d)
+			}
+		}
+	}
+	return
+}
+
+func flavorDrift(spec openstackstablesapccv2.FlavorSpec, f *flavors.Flavor, disabled bool) (drift []Drift) {
+	resource := fmt.Sprintf("flavor %s", spec.Name)
+	add := func(field string, desired, actual interface{}) {
+		if desired != actual {
+			drift = append(drift, Drift{Resource: resource, Field: field, Desired: desired, Actual: actual})
+		}
+	}
+	add("ram", spec.Ram, f.RAM)
+	add("vcpus", spec.Vcpus, f.VCPUs)
+	add("disk", spec.Disk, f.Disk)
+	add("swap", spec.Swap, f.Swap)
+	add("ephemeral", spec.Ephemeral, f.Ephemeral)
+	if spec.RxTxfactor > 0 {
+		add("rxtx_factor", float64(spec.RxTxfactor), f.RxTxFactor)
+	}
+	isPublic := true
+	if spec.IsPublic != nil {
+		isPublic = *spec.IsPublic
+	}
+	add("is_public", isPublic, f.IsPublic)
+	if spec.Disabled != nil {
+		add("disabled", *spec.Disabled, disabled)
+	}
+	return
+}
+
+// seedFlavorExtraSpecs adds missing, updates changed and removes undeclared extra specs of a flavor.
+func (n *Nova) seedFlavorExtraSpecs(flavorID string, specs map[string]string) (err error) {
+	current, err := flavors.ListExtraSpecs(n.Client, flavorID).Extract()
+	if err != nil {
+		return
+	}
+	changed := flavors.ExtraSpecsOpts{}
+	for k, v := range specs {
+		if cv, ok := current[k]; !ok || cv != v {
+			changed[k] = v
+		}
+	}
+	if len(changed) > 0 {
+		if _, err = flavors.CreateExtraSpecs(n.Client, flavorID, changed).Extract(); err != nil {
+			return fmt.Errorf("cannot set extra specs of flavor %s: %w", flavorID, err)
+		}
+	}
+	for k := range current {
+		if _, ok := specs[k]; ok {
+			continue
+		}
+		if err = flavors.DeleteExtraSpec(n.Client, flavorID, k).ExtractErr(); err != nil {
+			return fmt.Errorf("cannot delete extra spec %s of flavor %s: %w", k, flavorID, err)
+		}
+	}
+	return
+}
+
+// SeedFlavorAccess grants the projects access to a private flavor. If prune is set,
+// the access of all other projects is revoked. Public flavors are skipped.
+func (n *Nova) SeedFlavorAccess(flavorID string, projectIDs []string, prune bool) (err error) {
+	f, err := flavors.Get(n.Client, flavorID).Extract()
+	if err != nil {
+		return fmt.Errorf("cannot get flavor %s: %w", flavorID, err)
+	}
+	if f.IsPublic {
+		return
+	}
+	accesses, err := n.flavorAccess(flavorID)
+	if err != nil {
+		return
+	}
+	granted := make(map[string]bool, len(accesses))
+	for _, id := range accesses {
+		granted[id] = true
+	}
+	wanted := make(map[string]bool, len(projectIDs))
+	for _, id := range projectIDs {
+		wanted[id] = true
+		if granted[id] {
+			continue
+		}
+		if _, err = flavors.AddAccess(n.Client, flavorID, flavors.AddAccessOpts{Tenant: id}).Extract(); err != nil {
+			return fmt.Errorf("cannot grant project %s access to flavor %s: %w", id, flavorID, err)
+		}
+	}
+	if !prune {
+		return
+	}
+	for id := range granted {
+		if wanted[id] {
+			continue
+		}
+		if _, err = flavors.RemoveAccess(n.Client, flavorID, flavors.RemoveAccessOpts{Tenant: id}).Extract(); err != nil {
+			return fmt.Errorf("cannot revoke access of project %s to flavor %s: %w", id, flavorID, err)
+		}
+	}
+	return
+}
+
+// flavorAccess returns the ids of the projects which have access to a private flavor.
+func (n *Nova) flavorAccess(flavorID string) (projectIDs []string, err error) {
+	p, err := flavors.ListAccesses(n.Client, flavorID).AllPages()
+	if err != nil {
+		return
+	}
+	accesses, err := flavors.ExtractAccesses(p)
+	if err != nil {
+		return
+	}
+	for _, a := range accesses {
+		projectIDs = append(projectIDs, a.TenantID)
+	}
+	return
+}
+
+// SeedAggregate creates or updates a host aggregate, its metadata and, if the spec lists any, its hosts.
+// The returned changes describe the hosts added to or removed from the aggregate.
+func (n *Nova) SeedAggregate(spec openstackstablesapccv2.AggregateSpec) (updated *aggregates.Aggregate, changes []string, err error) {
+	p, err := aggregates.List(n.Client).AllPages()
+	if err != nil {
+		return
+	}
+	all, err := aggregates.ExtractAggregates(p)
+	if err != nil {
+		return
+	}
+	for i := range all {
+		if all[i].Name == spec.Name {
+			updated = &all[i]
+			break
+		}
+	}
+	if updated == nil {
+		updated, err = aggregates.Create(n.Client, aggregates.CreateOpts{
+			Name:             spec.Name,
+			AvailabilityZone: spec.AvailabilityZone,
+		}).Extract()
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot create aggregate %s: %w", spec.Name, err)
+		}
+	} else if spec.AvailabilityZone != "" && spec.AvailabilityZone != updated.AvailabilityZone {
+		updated, err = aggregates.Update(n.Client, updated.ID, aggregates.UpdateOpts{
+			AvailabilityZone: spec.AvailabilityZone,
+		}).Extract()
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot update aggregate %s: %w", spec.Name, err)
+		}
+	}
+
+	hosts := updated.Hosts
+	metadata := make(map[string]interface{})
+	for k, v := range spec.Metadata {
+		if cv, ok := updated.Metadata[k]; !ok || cv != v {
+			metadata[k] = v
+		}
+	}
+	for k := range updated.Metadata {
+		// the availability zone is part of the metadata, but managed by its own attribute,
+		// it can only be removed by unsetting the metadata key though
+		if _, ok := spec.Metadata[k]; !ok && (k != "availability_zone" || spec.AvailabilityZone == "") {
+			metadata[k] = nil
+		}
+	}
+	if len(metadata) > 0 {
+		updated, err = aggregates.SetMetadata(n.Client, updated.ID, aggregates.SetMetadataOpts{Metadata: metadata}).Extract()
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot set metadata of aggregate %s: %w", spec.Name, err)
+		}
+	}
+
+	if len(spec.Hosts) == 0 {
+		return
+	}
+	current := make(map[string]bool, len(hosts))
+	for _, h := range hosts {
+		current[h] = true
+	}
+	wanted := make(map[string]bool, len(spec.Hosts))
+	for _, h := range spec.Hosts {
+		wanted[h] = true
+		if current[h] {
+			continue
+		}
+		if updated, err = aggregates.AddHost(n.Client, updated.ID, aggregates.AddHostOpts{Host: h}).Extract(); err != nil {
+			return nil, changes, fmt.Errorf("cannot add host %s to aggregate %s: %w", h, spec.Name, err)
+		}
+		changes = append(changes, fmt.Sprintf("host %s added to aggregate %s", h, spec.Name))
+	}
+	for _, h := range hosts {
+		if wanted[h] {
+			continue
+		}
+		if updated, err = aggregates.RemoveHost(n.Client, updated.ID, aggregates.RemoveHostOpts{Host: h}).Extract(); err != nil {
+			return nil, changes, fmt.Errorf("cannot remove host %s from aggregate %s: %w", h, spec.Name, err)
+		}
+		changes = append(changes, fmt.Sprintf("host %s removed from aggregate %s", h, spec.Name))
+	}
+	return
+}
+
+// SeedComputeQuota updates the keys of the project's compute quota which differ from the spec.
+// project is the human readable project name used in the returned changes.
+func (n *Nova) SeedComputeQuota(projectID, project string, spec openstackstablesapccv2.ComputeQuotaSpec) (changes []openstackstablesapccv2.QuotaChange, err error) {
+	var current struct {
+		QuotaSet map[string]interface{} `json:"quota_set"`
+	}
+	if err = quotasets.Get(n.Client, projectID).ExtractInto(&current); err != nil {
+		return
+	}
+	opts, changes := diffQuota("compute", project, spec, current.QuotaSet)
+	if len(opts) == 0 {
+		return
+	}
+	if _, err = quotasets.Update(n.Client, projectID, opts).Extract(); err != nil {
+		return nil, fmt.Errorf("cannot update compute quota of project %s: %w", project, err)
+	}
+	return
+}
@@ -0,0 +1,128 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+)
+
+// gophercloud does not support the placement resource class and trait APIs (yet),
+// so the few requests the seeder needs are implemented here.
+
+// placementMicroversion is the first microversion supporting PUT /resource_classes/{name}.
+const placementMicroversion = "1.7"
+
+// custom resource classes and traits share the same naming rules
+var customNameRx = regexp.MustCompile(`^CUSTOM_[A-Z0-9_]+$`)
+
+type Placement struct {
+	Client *gophercloud.ServiceClient
+}
+
+func NewPlacement(client *gophercloud.ServiceClient) (p *Placement) {
+	client.Microversion = placementMicroversion
+	return &Placement{
+		Client: client,
+	}
+}
+
+func NewPlacementClient() (client *gophercloud.ServiceClient, err error) {
+	provider, err := newProviderClient()
+	if err != nil {
+		return
+	}
+	client, err = openstack.NewPlacementV1(provider, regionEndpointOpts())
+	return
+}
+
+// ValidateCustomName checks the naming rules of custom resource classes and traits.
+func ValidateCustomName(name string) error {
+	if len(name) > 255 || !customNameRx.MatchString(name) {
+		return fmt.Errorf("invalid name %s: must match %s and be at most 255 characters long", name, customNameRx)
+	}
+	return nil
+}
+
+// SeedResourceClasses creates the custom resource classes which do not exist yet.
+func (p *Placement) SeedResourceClasses(names []string) (err error) {
+	for _, n := range names {
+		if err = ValidateCustomName(n); err != nil {
+			return fmt.Errorf("resource class: %w", err)
+		}
+	}
+	for _, n := range names {
+		if err = p.put(p.Client.ServiceURL("resource_classes", n)); err != nil {
+			return fmt.Errorf("cannot create resource class %s: %w", n, err)
+		}
+	}
+	return
+}
+
+// SeedTraits creates the custom traits which do not exist yet.
+func (p *Placement) SeedTraits(names []string) (err error) {
+	for _, n := range names {
+		if err = ValidateCustomName(n); err != nil {
+			return fmt.Errorf("trait: %w", err)
+		}
+	}
+	for _, n := range names {
+		if err = p.put(p.Client.ServiceURL("traits", n)); err != nil {
+			return fmt.Errorf("cannot create trait %s: %w", n, err)
+		}
+	}
+	return
+}
+
+// PruneResourceClasses deletes all custom resource classes which are not declared.
+// Standard resource classes are never touched.
+func (p *Placement) PruneResourceClasses(declared []string) (deleted []string, err error) {
+	var r struct {
+		ResourceClasses []struct {
+			Name string `json:"name"`
+		} `json:"resource_classes"`
+	}
+	if _, err = p.Client.Get(p.Client.ServiceURL("resource_classes"), &r, nil); err != nil {
+		return
+	}
+	keep := make(map[string]bool, len(declared))
+	for _, n := range declared {
+		keep[n] = true
+	}
+	for _, rc := range r.ResourceClasses {
+		if !strings.HasPrefix(rc.Name, "CUSTOM_") || keep[rc.Name] {
+			continue
+		}
+		if _, err = p.Client.Delete(p.Client.ServiceURL("resource_classes", rc.Name), nil); err != nil {
+			return deleted, fmt.Errorf("cannot delete resource class %s: %w", rc.Name, err)
+		}
+		deleted = append(deleted, rc.Name)
+	}
+	return
+}
+
+// put creates a resource class or trait. Placement answers 204 if it exists already.
+func (p *Placement) put(url string) error {
+	_, err := p.Client.Put(url, nil, nil, &gophercloud.RequestOpts{
+		OkCodes: []int{201, 204},
+	})
+	return err
+}
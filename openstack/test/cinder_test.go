@@ -0,0 +1,193 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"testing"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+	"github.com/sapcc/openstack-seeder/openstack"
+	"github.com/stretchr/testify/assert"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+func TestSeedVolumeType(t *testing.T) {
+	private := false
+	specNotEqual := openstackstablesapccv2.VolumeTypeSpec{
+		Name:        "vmware",
+		Description: "vmware vvol volumes",
+		ExtraSpecs: map[string]string{
+			"volume_backend_name":    "vmware",
+			"vmware:storage_profile": "vvol",
+		},
+	}
+	specPrivate := openstackstablesapccv2.VolumeTypeSpec{
+		Name:        "hana",
+		Description: "hana volumes",
+		IsPublic:    &private,
+		Projects:    []string{"admin@monsoon3", "hana@monsoon3"},
+	}
+	specNotExist := openstackstablesapccv2.VolumeTypeSpec{
+		Name:       "netapp",
+		ExtraSpecs: map[string]string{"volume_backend_name": "netapp"},
+	}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleVolumeTypesSuccessfully(t, &actions)
+	HandleProjectLookupSuccessfully(t)
+
+	c := openstack.NewCinder(client.ServiceClient(), openstack.NewKeystone(client.ServiceClient()))
+	vt, _, err := c.SeedVolumeType(specNotEqual)
+	assert.NoError(t, err, "volume type and extra specs should be updated")
+	assert.Equal(t, "vmware vvol volumes", vt.Description)
+
+	vt, _, err = c.SeedVolumeType(specPrivate)
+	assert.NoError(t, err, "project access should be updated")
+	assert.Equal(t, "vt2", vt.ID)
+	assert.Equal(t, []string{"addProjectAccess p3"}, actions, "access should only be granted")
+
+	vt, _, err = c.SeedVolumeType(specNotExist)
+	assert.NoError(t, err, "volume type should be created")
+	assert.Equal(t, "vt3", vt.ID)
+
+	specPrivate.Projects = []string{"hana"}
+	_, _, err = c.SeedVolumeType(specPrivate)
+	assert.Error(t, err, "project names need a domain")
+}
+
+func TestPruneVolumeTypeAccess(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleVolumeTypesSuccessfully(t, &actions)
+
+	c := openstack.NewCinder(client.ServiceClient(), openstack.NewKeystone(client.ServiceClient()))
+	err := c.PruneVolumeTypeAccess("vmware", []string{"p1"})
+	assert.NoError(t, err, "public volume types should be skipped")
+
+	err = c.PruneVolumeTypeAccess("hana", []string{"p1", "p3"})
+	assert.NoError(t, err, "access should be revoked")
+	assert.Equal(t, []string{"removeProjectAccess p2"}, actions)
+
+	err = c.PruneVolumeTypeAccess("unknown", nil)
+	assert.True(t, openstack.IsNotFound(err))
+}
+
+func TestSeedVolumeTypeEncryption(t *testing.T) {
+	spec := openstackstablesapccv2.VolumeTypeSpec{
+		Name:        "vmware",
+		Description: "vmware vvol volumes",
+		ExtraSpecs: map[string]string{
+			"volume_backend_name":    "vmware",
+			"vmware:storage_profile": "vvol",
+		},
+		Encryption: &openstackstablesapccv2.VolumeTypeEncryptionSpec{
+			Provider: "luks",
+			Cipher:   "aes-xts-plain64",
+			KeySize:  512,
+		},
+	}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	HandleVolumeTypesSuccessfully(t, nil)
+	HandleVolumeTypeEncryptionSuccessfully(t)
+
+	c := openstack.NewCinder(client.ServiceClient(), nil)
+	_, drift, err := c.SeedVolumeType(spec)
+	assert.NoError(t, err, "the encryption of a volume type in use is drift")
+	if assert.Len(t, drift, 1) {
+		assert.Equal(t, "volume type vmware: encryption key_size is 256 instead of 512", drift[0].String())
+	}
+
+	spec.Encryption.ControlLocation = "hypervisor"
+	_, _, err = c.SeedVolumeType(spec)
+	assert.Error(t, err, "invalid control location should be rejected")
+}
+
+func TestSeedQosSpec(t *testing.T) {
+	spec := openstackstablesapccv2.QosSpecSpec{
+		Name:        "gold",
+		Consumer:    "front-end",
+		Specs:       map[string]string{"total_iops_sec": "5000"},
+		VolumeTypes: []string{"vmware", "hana"},
+	}
+	specNotExist := openstackstablesapccv2.QosSpecSpec{
+		Name:  "silver",
+		Specs: map[string]string{"total_iops_sec": "1000"},
+	}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	HandleVolumeTypesSuccessfully(t, nil)
+	var actions []string
+	HandleQosSpecsSuccessfully(t, &actions)
+
+	c := openstack.NewCinder(client.ServiceClient(), nil)
+	q, err := c.SeedQosSpec(spec)
+	assert.NoError(t, err, "qos spec should be updated")
+	assert.Equal(t, "q1", q.ID)
+	assert.Equal(t, []string{
+		`PUT q1 {"qos_specs":{"consumer":"front-end","total_iops_sec":"5000"}}`,
+		`PUT q1/delete_keys {"keys":["read_iops_sec"]}`,
+		"GET q1/associate vt2",
+		"GET q1/disassociate vt9",
+	}, actions)
+
+	actions = nil
+	q, err = c.SeedQosSpec(specNotExist)
+	assert.NoError(t, err, "qos spec should be created")
+	assert.Equal(t, "q2", q.ID)
+	assert.Empty(t, actions)
+
+	spec.Consumer = "everyone"
+	_, err = c.SeedQosSpec(spec)
+	assert.Error(t, err, "invalid consumer should be rejected")
+}
+
+func TestSeedVolumeQuota(t *testing.T) {
+	spec := openstackstablesapccv2.VolumeQuotaSpec{
+		Volumes:   10,
+		Gigabytes: 2000,
+		VolumeTypes: map[string]int{
+			"gigabytes_vmware": 1500,
+			"gigabytes_hana":   500,
+		},
+	}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	HandleVolumeTypesSuccessfully(t, nil)
+	HandleVolumeQuotaSuccessfully(t)
+
+	c := openstack.NewCinder(client.ServiceClient(), nil)
+	changes, err := c.SeedVolumeQuota("p2", "storage@monsoon3", spec, nil)
+	assert.NoError(t, err, "changed keys should be updated")
+	assert.Equal(t, []openstackstablesapccv2.QuotaChange{
+		{Service: "volume", Project: "storage@monsoon3", Resource: "gigabytes", Old: 1000, New: 2000},
+		{Service: "volume", Project: "storage@monsoon3", Resource: "gigabytes_hana", Old: 0, New: 500},
+		{Service: "volume", Project: "storage@monsoon3", Resource: "gigabytes_vmware", Old: -1, New: 1500},
+	}, changes)
+
+	spec.VolumeTypes = map[string]int{"gigabytes_netapp": 100}
+	_, err = c.SeedVolumeQuota("p2", "storage@monsoon3", spec, nil)
+	assert.Error(t, err, "unknown volume types should be rejected")
+
+	spec.VolumeTypes = map[string]int{"backups_vmware": 100}
+	_, err = c.SeedVolumeQuota("p2", "storage@monsoon3", spec, []string{"vmware"})
+	assert.Error(t, err, "backups cannot be limited per volume type")
+}
@@ -0,0 +1,92 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// ListAggregatesOutput provides a single page of host aggregates.
+const ListAggregatesOutput = `
+{
+    "aggregates": [
+        {
+            "id": 1,
+            "name": "bb091",
+            "availability_zone": "qa-de-1a",
+            "hosts": ["node001-bb091", "node002-bb091"],
+            "metadata": {
+                "availability_zone": "qa-de-1a",
+                "filter_tenant_id": "p1"
+            },
+            "deleted": false
+        }
+    ]
+}
+`
+
+// SetAggregateMetadataRequest provides the input to a SetMetadata request.
+const SetAggregateMetadataRequest = `
+{
+    "set_metadata": {
+        "metadata": {
+            "filter_tenant_id": null,
+            "hana": "true"
+        }
+    }
+}
+`
+
+// HandleAggregatesSuccessfully creates HTTP handlers at `/os-aggregates` on the test handler mux.
+// Aggregate `bb091` exists with two hosts, the requested actions are recorded in actions.
+func HandleAggregatesSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/os-aggregates", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, ListAggregatesOutput)
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, `{"aggregate": {"name": "bb092", "availability_zone": "qa-de-1b"}}`)
+
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"aggregate": {"id": 2, "name": "bb092", "availability_zone": "qa-de-1b", "hosts": [], "metadata": {"availability_zone": "qa-de-1b"}}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/os-aggregates/1/action", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "POST")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		var body map[string]json.RawMessage
+		th.AssertNoErr(t, json.NewDecoder(r.Body).Decode(&body))
+		for action, opts := range body {
+			*actions = append(*actions, action+" "+string(opts))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"aggregate": {"id": 1, "name": "bb091", "availability_zone": "qa-de-1a"}}`)
+	})
+}
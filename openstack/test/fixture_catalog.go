@@ -0,0 +1,115 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// ListCatalogServicesOutput provides a single page of Service results.
+const ListCatalogServicesOutput = `
+{
+    "services": [
+        {
+            "id": "1234",
+            "type": "compute",
+            "name": "nova",
+            "enabled": true
+        },
+        {
+            "id": "5678",
+            "type": "image",
+            "name": "glance",
+            "enabled": true
+        }
+    ]
+}
+`
+
+// ListCatalogEndpointsOutput provides a single page of Endpoint results.
+const ListCatalogEndpointsOutput = `
+{
+    "endpoints": [
+        {
+            "id": "e1",
+            "interface": "public",
+            "region": "eu-de-1",
+            "service_id": "1234",
+            "url": "https://compute-3.eu-de-1.example.com/v2.1",
+            "enabled": true
+        },
+        {
+            "id": "e2",
+            "interface": "internal",
+            "region": "eu-de-1",
+            "service_id": "1234",
+            "url": "http://nova-api.monsoon3:8774/v2.1",
+            "enabled": true
+        },
+        {
+            "id": "e3",
+            "interface": "internal",
+            "region": "eu-de-1",
+            "service_id": "1234",
+            "url": "http://nova-api.monsoon3:8774/v2.1",
+            "enabled": true
+        },
+        {
+            "id": "e4",
+            "interface": "public",
+            "region": "eu-de-2",
+            "service_id": "1234",
+            "url": "https://compute-3.eu-de-2.example.com/v2.1",
+            "enabled": true
+        },
+        {
+            "id": "e5",
+            "interface": "public",
+            "region": "eu-de-1",
+            "service_id": "5678",
+            "url": "https://image-3.eu-de-1.example.com",
+            "enabled": true
+        }
+    ]
+}
+`
+
+// HandleListCatalogSuccessfully creates HTTP handlers at `/services` and `/endpoints`
+// on the test handler mux that respond with the whole catalog.
+func HandleListCatalogSuccessfully(t *testing.T) {
+	th.Mux.HandleFunc("/services", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, ListCatalogServicesOutput)
+	})
+	th.Mux.HandleFunc("/endpoints", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, ListCatalogEndpointsOutput)
+	})
+}
@@ -0,0 +1,171 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// ListTOTPCredentialOutput provides a single page with the totp credential of a user.
+const ListTOTPCredentialOutput = `
+{
+    "credentials": [
+        {
+            "id": "3d3367228f9c7665266604462ec60029bcd83ad89614021a80b2eb879c572510",
+            "user_id": "bb5476fd12884539b41d5a88f838d773",
+            "type": "totp",
+            "blob": "GEZDGNBVGY3TQOJQ",
+            "project_id": ""
+        }
+    ]
+}
+`
+
+// ListEC2CredentialOutput provides a single page with an ec2 credential the user created on their own.
+const ListEC2CredentialOutput = `
+{
+    "credentials": [
+        {
+            "id": "5ebc4b0a7b2c0b5d8a1f4f3a2fa1b8e4f5b6c2a9e0d1c3b7a8f6e5d4c3b2a190",
+            "user_id": "bb5476fd12884539b41d5a88f838d773",
+            "type": "ec2",
+            "blob": "{\"access\": \"181920\", \"secret\": \"secretKey\"}",
+            "project_id": ""
+        }
+    ]
+}
+`
+
+// CreateCredentialRequest provides the input to a Create request.
+const CreateCredentialRequest = `
+{
+    "credential": {
+        "blob": "-----BEGIN CERTIFICATE-----",
+        "type": "cert",
+        "user_id": "bb5476fd12884539b41d5a88f838d773"
+    }
+}
+`
+
+// UpdateCredentialRequest provides the input to an Update request.
+const UpdateCredentialRequest = `
+{
+    "credential": {
+        "blob": "MFRGGZDFMZTWQ2LK"
+    }
+}
+`
+
+// CredentialOutput provides a Create or Update result without the blob.
+const CredentialOutput = `
+{
+    "credential": {
+        "id": "3d3367228f9c7665266604462ec60029bcd83ad89614021a80b2eb879c572510",
+        "user_id": "bb5476fd12884539b41d5a88f838d773"
+    }
+}
+`
+
+// HandleCredentialsSuccessfully creates HTTP handlers at `/credentials` on the test handler mux.
+// The user has a totp credential, but no cert credential.
+func HandleCredentialsSuccessfully(t *testing.T) {
+	th.Mux.HandleFunc("/credentials", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		switch r.Method {
+		case http.MethodGet:
+			th.TestFormValues(t, r, map[string]string{
+				"user_id": "bb5476fd12884539b41d5a88f838d773",
+				"type":    r.URL.Query().Get("type"),
+			})
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			if r.URL.Query().Get("type") == "totp" {
+				fmt.Fprintf(w, ListTOTPCredentialOutput)
+			} else {
+				fmt.Fprintf(w, `{"credentials": []}`)
+			}
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, CreateCredentialRequest)
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, CredentialOutput)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/credentials/3d3367228f9c7665266604462ec60029bcd83ad89614021a80b2eb879c572510", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "PATCH")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		th.TestJSONRequest(t, r, UpdateCredentialRequest)
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, CredentialOutput)
+	})
+}
+
+// HandleEC2CredentialsSuccessfully creates HTTP handlers at `/credentials` on the test handler mux.
+// The user has an ec2 credential with the access key 181920. The created and updated credentials
+// are recorded by their access key.
+func HandleEC2CredentialsSuccessfully(t *testing.T, actions *[]string) {
+	access := func(r *http.Request) string {
+		var body struct {
+			Credential struct {
+				Blob string `json:"blob"`
+			} `json:"credential"`
+		}
+		th.AssertNoErr(t, json.NewDecoder(r.Body).Decode(&body))
+		var blob struct {
+			Access string `json:"access"`
+		}
+		th.AssertNoErr(t, json.Unmarshal([]byte(body.Credential.Blob), &blob))
+		return blob.Access
+	}
+	th.Mux.HandleFunc("/credentials", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		switch r.Method {
+		case http.MethodGet:
+			th.TestFormValues(t, r, map[string]string{
+				"user_id": "bb5476fd12884539b41d5a88f838d773",
+				"type":    "ec2",
+			})
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, ListEC2CredentialOutput)
+		case http.MethodPost:
+			*actions = append(*actions, "create "+access(r))
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, CredentialOutput)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/credentials/5ebc4b0a7b2c0b5d8a1f4f3a2fa1b8e4f5b6c2a9e0d1c3b7a8f6e5d4c3b2a190", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "PATCH")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		*actions = append(*actions, "update "+access(r))
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, CredentialOutput)
+	})
+}
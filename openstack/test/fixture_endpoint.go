@@ -0,0 +1,90 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// CreateEndpointRequest provides the input to a Create request.
+const CreateEndpointRequest = `
+{
+    "endpoint": {
+        "interface": "internal",
+        "region_id": "eu-de-1",
+        "url": "http://nova.internal:8774/v2.1",
+        "service_id": "9876",
+        "enabled": true
+    }
+}
+`
+
+// UpdateEndpointRequest provides the input to an Update request.
+const UpdateEndpointRequest = `
+{
+    "endpoint": {
+        "interface": "public",
+        "region_id": "eu-de-1",
+        "url": "https://nova.example.com/v2.1",
+        "service_id": "9876",
+        "enabled": true
+    }
+}
+`
+
+// HandleEndpointsSuccessfully creates HTTP handlers at `/endpoints` on the test handler mux.
+// The public endpoint e1 of service 9876 in region eu-de-1 exists with an outdated url and disabled.
+// The requested changes are recorded in actions.
+func HandleEndpointsSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/endpoints", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			th.TestFormValues(t, r, map[string]string{"service_id": "9876", "region_id": "eu-de-1", "interface": r.URL.Query().Get("interface")})
+			w.WriteHeader(http.StatusOK)
+			if r.URL.Query().Get("interface") == "public" {
+				fmt.Fprintf(w, `{"endpoints": [{"id": "e1", "interface": "public", "region_id": "eu-de-1", "service_id": "9876", "url": "https://nova.example.com:8774/v2.1", "enabled": false}]}`)
+			} else {
+				fmt.Fprintf(w, `{"endpoints": []}`)
+			}
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, CreateEndpointRequest)
+			*actions = append(*actions, "create internal endpoint")
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"endpoint": {"id": "e2", "interface": "internal", "region_id": "eu-de-1", "service_id": "9876", "url": "http://nova.internal:8774/v2.1", "enabled": true}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/endpoints/e1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "PATCH")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		th.TestJSONRequest(t, r, UpdateEndpointRequest)
+		*actions = append(*actions, "update public endpoint e1")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"endpoint": {"id": "e1", "interface": "public", "region_id": "eu-de-1", "service_id": "9876", "url": "https://nova.example.com/v2.1", "enabled": true}}`)
+	})
+}
@@ -0,0 +1,282 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// ListFlavorsOutput provides a single page of flavors.
+const ListFlavorsOutput = `
+{
+    "flavors": [
+        {
+            "id": "1",
+            "name": "m1.small",
+            "ram": 2048,
+            "vcpus": 1,
+            "disk": 10,
+            "swap": "",
+            "rxtx_factor": 1.0,
+            "os-flavor-access:is_public": true,
+            "OS-FLV-EXT-DATA:ephemeral": 0,
+            "OS-FLV-DISABLED:disabled": false
+        }
+    ]
+}
+`
+
+// GetFlavorOutput provides a Get result.
+const GetFlavorOutput = `
+{
+    "flavor": {
+        "id": "1",
+        "name": "m1.small",
+        "ram": 2048,
+        "vcpus": 1,
+        "disk": 10,
+        "swap": "",
+        "rxtx_factor": 1.0,
+        "os-flavor-access:is_public": true,
+        "OS-FLV-EXT-DATA:ephemeral": 0,
+        "OS-FLV-DISABLED:disabled": false
+    }
+}
+`
+
+// CreateFlavorRequest provides the input to a Create request.
+const CreateFlavorRequest = `
+{
+    "flavor": {
+        "name": "m1.new",
+        "ram": 1024,
+        "vcpus": 1,
+        "disk": 0,
+        "swap": 0,
+        "OS-FLV-EXT-DATA:ephemeral": 0
+    }
+}
+`
+
+// ListExtraSpecsOutput provides the extra specs of flavor `1`.
+const ListExtraSpecsOutput = `
+{
+    "extra_specs": {
+        "hw:cpu_policy": "shared",
+        "quota:disk_read_iops_sec": "1000"
+    }
+}
+`
+
+// CreateExtraSpecsRequest provides the changed extra specs of flavor `1`.
+const CreateExtraSpecsRequest = `
+{
+    "extra_specs": {
+        "hw:cpu_policy": "dedicated",
+        "hw:mem_page_size": "large"
+    }
+}
+`
+
+// HandleFlavorsSuccessfully creates HTTP handlers at `/flavors` on the test handler mux.
+// Flavor `m1.small` exists with id `1`, flavor `m1.new` is created with id `2`.
+func HandleFlavorsSuccessfully(t *testing.T) {
+	th.Mux.HandleFunc("/flavors/detail", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		th.TestFormValues(t, r, map[string]string{"is_public": "None"})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, ListFlavorsOutput)
+	})
+	th.Mux.HandleFunc("/flavors", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "POST")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		th.TestJSONRequest(t, r, CreateFlavorRequest)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"flavor": {"id": "2", "name": "m1.new", "ram": 1024, "vcpus": 1, "disk": 0}}`)
+	})
+	th.Mux.HandleFunc("/flavors/1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, GetFlavorOutput)
+	})
+	th.Mux.HandleFunc("/flavors/1/os-extra_specs", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, ListExtraSpecsOutput)
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, CreateExtraSpecsRequest)
+
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, CreateExtraSpecsRequest)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/flavors/1/os-extra_specs/quota:disk_read_iops_sec", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "DELETE")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.WriteHeader(http.StatusOK)
+	})
+	th.Mux.HandleFunc("/flavors/2/os-extra_specs", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"extra_specs": {}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// ListFlavorAccessOutput provides the access list of the private flavor `3`.
+const ListFlavorAccessOutput = `
+{
+    "flavor_access": [
+        {
+            "flavor_id": "3",
+            "tenant_id": "p1"
+        },
+        {
+            "flavor_id": "3",
+            "tenant_id": "p2"
+        }
+    ]
+}
+`
+
+// HandleFlavorAccessSuccessfully creates HTTP handlers for the private flavor `3` on the test handler mux.
+// Projects `p1` and `p2` have access to it, the requested access changes are recorded in actions.
+func HandleFlavorAccessSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/flavors/3", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"flavor": {"id": "3", "name": "hana.large", "os-flavor-access:is_public": false}}`)
+	})
+	th.Mux.HandleFunc("/flavors/3/os-flavor-access", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, ListFlavorAccessOutput)
+	})
+	th.Mux.HandleFunc("/flavors/3/action", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "POST")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		var body map[string]struct {
+			Tenant string `json:"tenant"`
+		}
+		th.AssertNoErr(t, json.NewDecoder(r.Body).Decode(&body))
+		for action, opts := range body {
+			*actions = append(*actions, action+" "+opts.Tenant)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, ListFlavorAccessOutput)
+	})
+}
+
+// HandleRecreateFlavorSuccessfully creates HTTP handlers for the private flavor `4` on the test handler mux.
+// Flavor `4` has 2048 MB ram and projects `p1` and `p2` have access to it,
+// the requested deletion, creation and access changes are recorded in actions.
+func HandleRecreateFlavorSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/flavors/4", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"flavor": {"id": "4", "name": "hana.small", "ram": 2048, "vcpus": 1, "disk": 0, "os-flavor-access:is_public": false}}`)
+		case http.MethodDelete:
+			*actions = append(*actions, "delete 4")
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/flavors", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "POST")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		var body struct {
+			Flavor struct {
+				ID  string `json:"id"`
+				RAM int    `json:"ram"`
+			} `json:"flavor"`
+		}
+		th.AssertNoErr(t, json.NewDecoder(r.Body).Decode(&body))
+		*actions = append(*actions, fmt.Sprintf("create %s ram %d", body.Flavor.ID, body.Flavor.RAM))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"flavor": {"id": "4", "name": "hana.small", "ram": 4096, "vcpus": 1, "disk": 0, "os-flavor-access:is_public": false}}`)
+	})
+	th.Mux.HandleFunc("/flavors/4/os-flavor-access", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"flavor_access": [{"flavor_id": "4", "tenant_id": "p1"}, {"flavor_id": "4", "tenant_id": "p2"}]}`)
+	})
+	th.Mux.HandleFunc("/flavors/4/action", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "POST")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		var body map[string]struct {
+			Tenant string `json:"tenant"`
+		}
+		th.AssertNoErr(t, json.NewDecoder(r.Body).Decode(&body))
+		for action, opts := range body {
+			*actions = append(*actions, action+" "+opts.Tenant)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"flavor_access": []}`)
+	})
+	th.Mux.HandleFunc("/flavors/4/os-extra_specs", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"extra_specs": {}}`)
+	})
+}
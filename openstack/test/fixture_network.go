@@ -0,0 +1,223 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// LookupNetworks maps the project ids and names of networks to their ids.
+// Lookups without project id match the name in all projects.
+var LookupNetworks = map[string]string{
+	"p1/private": "n1",
+	"p2/storage": "n2",
+	"p1/ext":     "ext1",
+}
+
+// LookupSubnets maps the project ids and names of subnets to their ids.
+var LookupSubnets = map[string]string{
+	"p1/private-v4": "s1",
+	"p2/storage-v4": "s2",
+	"p1/ext-v4":     "ext-s1",
+}
+
+func handleLookup(t *testing.T, resource string, ids map[string]string) {
+	th.Mux.HandleFunc("/"+resource, func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		projectID, name := r.URL.Query().Get("project_id"), r.URL.Query().Get("name")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		var found []string
+		for key, id := range ids {
+			if key == projectID+"/"+name || projectID == "" && strings.HasSuffix(key, "/"+name) {
+				found = append(found, fmt.Sprintf(`{"id": "%s", "name": "%s"}`, id, name))
+			}
+		}
+		fmt.Fprintf(w, `{"%s": [%s]}`, resource, strings.Join(found, ", "))
+	})
+}
+
+// HandleNetworkLookupSuccessfully creates HTTP handlers at `/networks` and `/subnets` on the
+// test handler mux, which resolve the LookupNetworks and LookupSubnets by project id and name.
+func HandleNetworkLookupSuccessfully(t *testing.T) {
+	handleLookup(t, "networks", LookupNetworks)
+	handleLookup(t, "subnets", LookupSubnets)
+}
+
+// ListNetworksOutput provides the existing network `private` of project p1.
+const ListNetworksOutput = `
+{
+    "networks": [
+        {
+            "id": "n1",
+            "name": "private",
+            "description": "",
+            "project_id": "p1",
+            "admin_state_up": true,
+            "shared": false,
+            "router:external": false,
+            "port_security_enabled": true,
+            "provider:network_type": "vlan",
+            "provider:physical_network": "physnet1",
+            "provider:segmentation_id": 100,
+            "tags": ["a"]
+        }
+    ]
+}
+`
+
+// CreateNetworkRequest provides the input to a Create request.
+const CreateNetworkRequest = `
+{
+    "network": {
+        "name": "public",
+        "description": "",
+        "project_id": "p1",
+        "router:external": true,
+        "provider:network_type": "flat",
+        "provider:physical_network": "physnet2"
+    }
+}
+`
+
+// ListSubnetsOutput provides the existing subnet `private-v4` of network n1.
+const ListSubnetsOutput = `
+{
+    "subnets": [
+        {
+            "id": "s1",
+            "name": "private-v4",
+            "description": "",
+            "network_id": "n1",
+            "project_id": "p1",
+            "ip_version": 4,
+            "cidr": "10.0.0.0/24",
+            "gateway_ip": "10.0.0.1",
+            "enable_dhcp": true,
+            "dns_nameservers": ["10.0.0.2"],
+            "allocation_pools": [{"start": "10.0.0.10", "end": "10.0.0.100"}, {"start": "10.0.0.200", "end": "10.0.0.250"}],
+            "host_routes": [],
+            "tags": []
+        }
+    ]
+}
+`
+
+// CreateSubnetRequest provides the input to a Create request.
+const CreateSubnetRequest = `
+{
+    "subnet": {
+        "name": "public-v4",
+        "description": "",
+        "network_id": "n3",
+        "project_id": "p1",
+        "ip_version": 4,
+        "cidr": "192.168.0.0/24",
+        "enable_dhcp": false,
+        "host_routes": [{"destination": "10.1.0.0/16", "nexthop": "192.168.0.254"}]
+    }
+}
+`
+
+// HandleNetworksSuccessfully creates HTTP handlers at `/networks` and `/subnets` on the test handler mux.
+// The network `private` (n1) of project p1 and its subnet `private-v4` (s1) exist. The requested changes are recorded in actions.
+func HandleNetworksSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/networks", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			if r.URL.Query().Get("name") == "private" && r.URL.Query().Get("project_id") == "p1" {
+				fmt.Fprintf(w, ListNetworksOutput)
+			} else {
+				fmt.Fprintf(w, `{"networks": []}`)
+			}
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, CreateNetworkRequest)
+			*actions = append(*actions, "create network public")
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"network": {"id": "n3", "name": "public", "project_id": "p1", "router:external": true}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/networks/n1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "PUT")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		th.TestJSONRequest(t, r, `{"network": {"shared": true}}`)
+		*actions = append(*actions, "update network private")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"network": {"id": "n1", "name": "private", "shared": true, "tags": ["a"]}}`)
+	})
+	th.Mux.HandleFunc("/networks/n1/tags", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "PUT")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		var body struct {
+			Tags []string `json:"tags"`
+		}
+		th.AssertNoErr(t, json.NewDecoder(r.Body).Decode(&body))
+		*actions = append(*actions, fmt.Sprintf("tag network private %v", body.Tags))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"tags": ["a", "b"]}`)
+	})
+	th.Mux.HandleFunc("/subnets", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			if r.URL.Query().Get("name") == "private-v4" && r.URL.Query().Get("network_id") == "n1" {
+				fmt.Fprintf(w, ListSubnetsOutput)
+			} else {
+				fmt.Fprintf(w, `{"subnets": []}`)
+			}
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, CreateSubnetRequest)
+			*actions = append(*actions, "create subnet public-v4")
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"subnet": {"id": "s3", "name": "public-v4", "network_id": "n3"}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/subnets/s1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "PUT")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		th.TestJSONRequest(t, r, `{"subnet": {"dns_nameservers": ["10.0.0.2", "10.0.0.3"]}}`)
+		*actions = append(*actions, "update subnet private-v4")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"subnet": {"id": "s1", "name": "private-v4", "network_id": "n1"}}`)
+	})
+}
@@ -0,0 +1,76 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// ListResourceClassesOutput provides a List result.
+const ListResourceClassesOutput = `
+{
+    "resource_classes": [
+        {"name": "VCPU"},
+        {"name": "MEMORY_MB"},
+        {"name": "CUSTOM_BAREMETAL_LARGE"},
+        {"name": "CUSTOM_BAREMETAL_OLD"}
+    ]
+}
+`
+
+// HandlePlacementSuccessfully creates HTTP handlers at `/resource_classes` and `/traits`
+// on the test handler mux. `CUSTOM_BAREMETAL_LARGE` exists already, the deleted resource
+// classes are recorded in deleted.
+func HandlePlacementSuccessfully(t *testing.T, deleted *[]string) {
+	th.Mux.HandleFunc("/resource_classes", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, ListResourceClassesOutput)
+	})
+	th.Mux.HandleFunc("/resource_classes/", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		th.TestHeader(t, r, "OpenStack-API-Version", "placement 1.7")
+		name := r.URL.Path[len("/resource_classes/"):]
+		switch r.Method {
+		case http.MethodPut:
+			if name == "CUSTOM_BAREMETAL_LARGE" {
+				w.WriteHeader(http.StatusNoContent)
+			} else {
+				w.WriteHeader(http.StatusCreated)
+			}
+		case http.MethodDelete:
+			*deleted = append(*deleted, name)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/traits/CUSTOM_HANA", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "PUT")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.WriteHeader(http.StatusCreated)
+	})
+}
@@ -0,0 +1,161 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// PortOutput is the existing port `vip` of project p1 in the network `private` (n1).
+const PortOutput = `
+{
+    "id": "pt1",
+    "name": "vip",
+    "description": "",
+    "network_id": "n1",
+    "project_id": "p1",
+    "mac_address": "fa:16:3e:00:00:01",
+    "fixed_ips": [{"subnet_id": "s1", "ip_address": "10.0.0.5"}],
+    "allowed_address_pairs": [{"ip_address": "10.0.0.100", "mac_address": "fa:16:3e:00:00:01"}],
+    "tags": []
+}
+`
+
+// CreatePortRequest provides the input to a Create request.
+const CreatePortRequest = `
+{
+    "port": {
+        "name": "appliance",
+        "network_id": "n1",
+        "project_id": "p1",
+        "mac_address": "fa:16:3e:00:00:10",
+        "fixed_ips": [{"subnet_id": "s1", "ip_address": "10.0.0.10"}]
+    }
+}
+`
+
+// CreateFloatingIPRequest provides the input to a Create request.
+const CreateFloatingIPRequest = `
+{
+    "floatingip": {
+        "floating_network_id": "n2",
+        "floating_ip_address": "192.0.2.11",
+        "project_id": "p1"
+    }
+}
+`
+
+// HandlePortsSuccessfully creates HTTP handlers at `/networks`, `/subnets`, `/ports` and `/floatingips` on the test handler mux.
+// The port `vip` (pt1) of project p1 exists in the network `private` (n1) with the subnet `private-v4` (s1).
+// The floating ip 192.0.2.10 (fip1) of the external network `ext` (n2) is allocated by project p1, 192.0.2.12 by project p2.
+// The requested changes are recorded in actions.
+func HandlePortsSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/networks", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		q := r.URL.Query()
+		switch {
+		case q.Get("name") == "private" && q.Get("project_id") == "p1":
+			fmt.Fprintf(w, `{"networks": [{"id": "n1", "name": "private", "project_id": "p1"}]}`)
+		case q.Get("name") == "ext" && q.Get("project_id") == "":
+			fmt.Fprintf(w, `{"networks": [{"id": "n2", "name": "ext", "project_id": "admin", "router:external": true}]}`)
+		default:
+			fmt.Fprintf(w, `{"networks": []}`)
+		}
+	})
+	th.Mux.HandleFunc("/subnets", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Query().Get("name") == "private-v4" && r.URL.Query().Get("project_id") == "p1" {
+			fmt.Fprintf(w, `{"subnets": [{"id": "s1", "name": "private-v4", "network_id": "n1", "project_id": "p1"}]}`)
+		} else {
+			fmt.Fprintf(w, `{"subnets": []}`)
+		}
+	})
+	th.Mux.HandleFunc("/ports", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			if r.URL.Query().Get("name") == "vip" && r.URL.Query().Get("project_id") == "p1" {
+				fmt.Fprintf(w, `{"ports": [%s]}`, PortOutput)
+			} else {
+				fmt.Fprintf(w, `{"ports": []}`)
+			}
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, CreatePortRequest)
+			*actions = append(*actions, "create port appliance")
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"port": {"id": "pt2", "name": "appliance", "network_id": "n1", "mac_address": "fa:16:3e:00:00:10", "fixed_ips": [{"subnet_id": "s1", "ip_address": "10.0.0.10"}]}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/ports/pt1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "PUT")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		recordUpdate(t, r, "port", "vip", actions)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"port": %s}`, PortOutput)
+	})
+	th.Mux.HandleFunc("/floatingips", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			switch r.URL.Query().Get("floating_ip_address") {
+			case "192.0.2.10":
+				fmt.Fprintf(w, `{"floatingips": [{"id": "fip1", "floating_ip_address": "192.0.2.10", "floating_network_id": "n2", "project_id": "p1"}]}`)
+			case "192.0.2.12":
+				fmt.Fprintf(w, `{"floatingips": [{"id": "fip3", "floating_ip_address": "192.0.2.12", "floating_network_id": "n2", "project_id": "p2"}]}`)
+			default:
+				fmt.Fprintf(w, `{"floatingips": []}`)
+			}
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, CreateFloatingIPRequest)
+			*actions = append(*actions, "create floating ip 192.0.2.11")
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"floatingip": {"id": "fip2", "floating_ip_address": "192.0.2.11", "floating_network_id": "n2", "project_id": "p1"}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/floatingips/fip1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "PUT")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		recordUpdate(t, r, "floatingip", "192.0.2.10", actions)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"floatingip": {"id": "fip1", "floating_ip_address": "192.0.2.10", "floating_network_id": "n2", "project_id": "p1", "port_id": "pt1", "fixed_ip_address": "10.0.0.5"}}`)
+	})
+}
@@ -0,0 +1,85 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// LookupProjects maps the project names of domain `monsoon3` to their ids.
+var LookupProjects = map[string]string{
+	"admin":   "p1",
+	"storage": "p2",
+	"hana":    "p3",
+}
+
+// HandleProjectLookupSuccessfully creates HTTP handlers at `/domains` and `/projects` on the
+// test handler mux, which resolve the names of domain `monsoon3` and its LookupProjects.
+func HandleProjectLookupSuccessfully(t *testing.T) {
+	th.Mux.HandleFunc("/domains", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		th.TestFormValues(t, r, map[string]string{"name": "monsoon3"})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"domains": [{"id": "d1", "name": "monsoon3"}]}`)
+	})
+	th.Mux.HandleFunc("/projects", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		name := r.URL.Query().Get("name")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if id, ok := LookupProjects[name]; ok && r.URL.Query().Get("domain_id") == "d1" {
+			fmt.Fprintf(w, `{"projects": [{"id": "%s", "name": "%s", "domain_id": "d1"}]}`, id, name)
+		} else {
+			fmt.Fprintf(w, `{"projects": []}`)
+		}
+	})
+}
+
+// HandleDomainLookupFailure creates an HTTP handler at `/domains` on the test handler mux,
+// which fails as if keystone was unavailable.
+func HandleDomainLookupFailure(t *testing.T) {
+	th.Mux.HandleFunc("/domains", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+}
+
+// HandleTokenSuccessfully creates an HTTP handler at `/auth/tokens` on the test handler mux,
+// which validates the token of the client as scoped to project `cloud_admin` (p9).
+func HandleTokenSuccessfully(t *testing.T) {
+	th.Mux.HandleFunc("/auth/tokens", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		th.TestHeader(t, r, "X-Subject-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"token": {"project": {"id": "p9", "name": "cloud_admin", "domain": {"id": "d1", "name": "monsoon3"}}}}`)
+	})
+}
@@ -0,0 +1,108 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// QosPolicyOutput is the existing qos policy `gold` of project p1 with an egress bandwidth limit,
+// a dscp marking and a minimum bandwidth rule.
+const QosPolicyOutput = `
+{
+    "id": "qp1",
+    "name": "gold",
+    "description": "",
+    "project_id": "p1",
+    "shared": false,
+    "is_default": false,
+    "rules": [
+        {"id": "rl1", "type": "bandwidth_limit", "max_kbps": 1000, "max_burst_kbps": 100, "direction": "egress"},
+        {"id": "rl2", "type": "dscp_marking", "dscp_mark": 26},
+        {"id": "rl3", "type": "minimum_bandwidth", "min_kbps": 500, "direction": "egress"}
+    ]
+}
+`
+
+// HandleQosPoliciesSuccessfully creates HTTP handlers at `/qos/policies` on the test handler mux.
+// The qos policy `gold` (qp1) of project p1 exists. The requested changes are recorded in actions.
+func HandleQosPoliciesSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/qos/policies", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			projectID := r.URL.Query().Get("project_id")
+			w.WriteHeader(http.StatusOK)
+			if r.URL.Query().Get("name") == "gold" && (projectID == "p1" || projectID == "") {
+				fmt.Fprintf(w, `{"policies": [%s]}`, QosPolicyOutput)
+			} else {
+				fmt.Fprintf(w, `{"policies": []}`)
+			}
+		case http.MethodPost:
+			var body struct {
+				Policy struct {
+					Name      string `json:"name"`
+					ProjectID string `json:"project_id"`
+				} `json:"policy"`
+			}
+			th.AssertNoErr(t, json.NewDecoder(r.Body).Decode(&body))
+			*actions = append(*actions, fmt.Sprintf("create qos policy %s in %s", body.Policy.Name, body.Policy.ProjectID))
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"policy": {"id": "qp2", "name": "%s", "project_id": "%s", "shared": true, "rules": []}}`, body.Policy.Name, body.Policy.ProjectID)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	// changes of the policies and their rules are recorded as method, path and sorted attributes
+	th.Mux.HandleFunc("/qos/policies/", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		action := r.Method + " " + strings.TrimPrefix(r.URL.Path, "/qos/policies/")
+		if r.Method == http.MethodDelete {
+			*actions = append(*actions, action)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		var body map[string]map[string]json.RawMessage
+		th.AssertNoErr(t, json.NewDecoder(r.Body).Decode(&body))
+		for resource, attrs := range body {
+			var keys []string
+			for k, v := range attrs {
+				keys = append(keys, fmt.Sprintf("%s=%s", k, v))
+			}
+			sort.Strings(keys)
+			*actions = append(*actions, action+" "+strings.Join(keys, " "))
+
+			w.Header().Set("Content-Type", "application/json")
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusCreated)
+			} else {
+				w.WriteHeader(http.StatusOK)
+			}
+			fmt.Fprintf(w, `{"%s": {"id": "new"}}`, resource)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// GetComputeQuotaOutput provides a Get result.
+const GetComputeQuotaOutput = `
+{
+    "quota_set": {
+        "id": "p1",
+        "cores": 20,
+        "instances": 10,
+        "key_pairs": 100,
+        "metadata_items": 128,
+        "ram": 51200,
+        "server_groups": 10,
+        "server_group_members": 10
+    }
+}
+`
+
+// UpdateComputeQuotaRequest provides the input to an Update request.
+const UpdateComputeQuotaRequest = `
+{
+    "quota_set": {
+        "cores": 40,
+        "ram": 102400
+    }
+}
+`
+
+// HandleComputeQuotaSuccessfully creates HTTP handlers at `/os-quota-sets/p1` on the test handler mux.
+func HandleComputeQuotaSuccessfully(t *testing.T) {
+	th.Mux.HandleFunc("/os-quota-sets/p1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, GetComputeQuotaOutput)
+		case http.MethodPut:
+			th.TestJSONRequest(t, r, UpdateComputeQuotaRequest)
+
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, GetComputeQuotaOutput)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// GetVolumeQuotaOutput provides a Get result.
+const GetVolumeQuotaOutput = `
+{
+    "quota_set": {
+        "id": "p2",
+        "volumes": 10,
+        "gigabytes": 1000,
+        "snapshots": 10,
+        "backups": 10,
+        "gigabytes_vmware": -1,
+        "volumes_vmware": -1
+    }
+}
+`
+
+// UpdateVolumeQuotaRequest provides the input to an Update request.
+const UpdateVolumeQuotaRequest = `
+{
+    "quota_set": {
+        "gigabytes": 2000,
+        "gigabytes_hana": 500,
+        "gigabytes_vmware": 1500
+    }
+}
+`
+
+// HandleVolumeQuotaSuccessfully creates HTTP handlers at `/os-quota-sets/p2` on the test handler mux.
+func HandleVolumeQuotaSuccessfully(t *testing.T) {
+	th.Mux.HandleFunc("/os-quota-sets/p2", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, GetVolumeQuotaOutput)
+		case http.MethodPut:
+			th.TestJSONRequest(t, r, UpdateVolumeQuotaRequest)
+
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, GetVolumeQuotaOutput)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// HandleNetworkQuotaSuccessfully creates HTTP handlers at `/quotas/p1` for neutron and `/v2.0/quotas/p1` for octavia
+// on the test handler mux. The requested changes are recorded in actions.
+func HandleNetworkQuotaSuccessfully(t *testing.T, actions *[]string) {
+	for path, output := range map[string]string{
+		"/quotas/p1":      `{"quota": {"network": 10, "port": 50, "router": 10, "loadbalancer": 10, "listener": 10}}`,
+		"/v2.0/quotas/p1": `{"quota": {"load_balancer": 10, "listener": -1, "member": -1, "pool": -1, "health_monitor": -1}}`,
+	} {
+		path, output := path, output
+		th.Mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+			w.Header().Set("Content-Type", "application/json")
+			switch r.Method {
+			case http.MethodGet:
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprintf(w, output)
+			case http.MethodPut:
+				recordUpdate(t, r, "quota", path, actions)
+
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprintf(w, output)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		})
+	}
+}
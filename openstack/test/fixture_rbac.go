@@ -0,0 +1,124 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// ListRBACPoliciesOutput provides the RBAC policies sharing network n1 with the projects p2 and p3.
+const ListRBACPoliciesOutput = `
+{
+    "rbac_policies": [
+        {"id": "rbac1", "object_type": "network", "object_id": "n1", "action": "access_as_shared", "target_tenant": "p2", "project_id": "p1"},
+        {"id": "rbac2", "object_type": "network", "object_id": "n1", "action": "access_as_shared", "target_tenant": "p3", "project_id": "p1"}
+    ]
+}
+`
+
+// ListSharedNetworkRBACPoliciesOutput provides the RBAC policies of the shared network n4,
+// which neutron shares with all projects itself.
+const ListSharedNetworkRBACPoliciesOutput = `
+{
+    "rbac_policies": [
+        {"id": "rbac4", "object_type": "network", "object_id": "n4", "action": "access_as_shared", "target_tenant": "*", "project_id": "p1"},
+        {"id": "rbac5", "object_type": "network", "object_id": "n4", "action": "access_as_shared", "target_tenant": "p2", "project_id": "p1"}
+    ]
+}
+`
+
+// ListSharedQosPolicyRBACPoliciesOutput provides the RBAC policies of the shared qos policy q1,
+// which neutron shares with all projects itself.
+const ListSharedQosPolicyRBACPoliciesOutput = `
+{
+    "rbac_policies": [
+        {"id": "rbac6", "object_type": "qos_policy", "object_id": "q1", "action": "access_as_shared", "target_tenant": "*", "project_id": "p1"},
+        {"id": "rbac7", "object_type": "qos_policy", "object_id": "q1", "action": "access_as_shared", "target_tenant": "p3", "project_id": "p1"}
+    ]
+}
+`
+
+// HandleRBACPoliciesSuccessfully creates HTTP handlers at `/rbac-policies` on the test handler mux.
+// Network n1 is shared with p2 and p3, the shared network n4 with all projects and p2 and
+// the shared qos policy q1 with all projects and p3.
+// The requested changes are recorded in actions.
+func HandleRBACPoliciesSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/rbac-policies", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			objectType, objectID := r.URL.Query().Get("object_type"), r.URL.Query().Get("object_id")
+			th.TestFormValues(t, r, map[string]string{"object_type": objectType, "object_id": objectID, "action": "access_as_shared"})
+
+			w.WriteHeader(http.StatusOK)
+			switch objectID {
+			case "n4":
+				fmt.Fprintf(w, ListSharedNetworkRBACPoliciesOutput)
+			case "q1":
+				th.CheckEquals(t, "qos_policy", objectType)
+				fmt.Fprintf(w, ListSharedQosPolicyRBACPoliciesOutput)
+			default:
+				th.CheckEquals(t, "network", objectType)
+				fmt.Fprintf(w, ListRBACPoliciesOutput)
+			}
+		case http.MethodPost:
+			var body struct {
+				Policy map[string]string `json:"rbac_policy"`
+			}
+			th.AssertNoErr(t, json.NewDecoder(r.Body).Decode(&body))
+			*actions = append(*actions, fmt.Sprintf("create %s %s %s %s", body.Policy["object_type"], body.Policy["object_id"], body.Policy["action"], body.Policy["target_tenant"]))
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"rbac_policy": {"id": "rbac3", "target_tenant": "%s"}}`, body.Policy["target_tenant"])
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	for _, id := range []string{"rbac2", "rbac4", "rbac5", "rbac6", "rbac7"} {
+		id := id
+		th.Mux.HandleFunc("/rbac-policies/"+id, func(w http.ResponseWriter, r *http.Request) {
+			th.TestMethod(t, r, "DELETE")
+			th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+			*actions = append(*actions, "delete "+id)
+
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+	th.Mux.HandleFunc("/networks/n4", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"network": {"id": "n4", "name": "shared", "shared": true, "router:external": false}}`)
+	})
+	th.Mux.HandleFunc("/qos/policies/q1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"policy": {"id": "q1", "name": "shared", "shared": true}}`)
+	})
+}
@@ -0,0 +1,118 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// GetRegionOutput provides a Get result of an existing child region.
+const GetRegionOutput = `
+{
+    "region": {
+        "id": "eu-de-1",
+        "description": "some description",
+        "parent_region_id": "eu",
+        "links": {
+            "self": "https://example.com/identity/v3/regions/eu-de-1"
+        }
+    }
+}
+`
+
+// CreateRegionRequest provides the input to a Create request.
+const CreateRegionRequest = `
+{
+    "region": {
+        "id": "eu",
+        "description": "europe"
+    }
+}
+`
+
+// CreateRegionOutput provides a Create result.
+const CreateRegionOutput = `
+{
+    "region": {
+        "id": "eu",
+        "description": "europe",
+        "parent_region_id": null
+    }
+}
+`
+
+// UpdateRegionRequest provides the input to an Update request.
+const UpdateRegionRequest = `
+{
+    "region": {
+        "description": "some description",
+        "parent_region_id": null
+    }
+}
+`
+
+// UpdateRegionOutput provides an Update result.
+const UpdateRegionOutput = `
+{
+    "region": {
+        "id": "eu-de-1",
+        "description": "some description",
+        "parent_region_id": null
+    }
+}
+`
+
+// HandleRegionsSuccessfully creates HTTP handlers at `/regions` on the test handler
+// mux. The region `eu` does not exist yet and gets created, `eu-de-1` exists as a
+// child of `eu` and can be updated.
+func HandleRegionsSuccessfully(t *testing.T) {
+	th.Mux.HandleFunc("/regions/eu", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.WriteHeader(http.StatusNotFound)
+	})
+	th.Mux.HandleFunc("/regions", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "POST")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		th.TestJSONRequest(t, r, CreateRegionRequest)
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, CreateRegionOutput)
+	})
+	th.Mux.HandleFunc("/regions/eu-de-1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, GetRegionOutput)
+		case http.MethodPatch:
+			th.TestJSONRequest(t, r, UpdateRegionRequest)
+
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, UpdateRegionOutput)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
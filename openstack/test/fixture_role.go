@@ -41,9 +41,8 @@ const ListRoleOutput = `
             "links": {
                 "self": "http://example.com/identity/v3/roles/2844b2a08be147a08ef58317d6471f1f"
             },
-            "name": "some
-			_role",
-			"extra": {
+            "name": "some_role",
+            "extra": {
                 "description": "some description"
             }
         },
@@ -216,3 +215,88 @@ func HandleUpdateRoleSuccessfully(t *testing.T) {
 		fmt.Fprintf(w, UpdateRoleOutput)
 	})
 }
+
+// ListDomainOutput provides a single page with the domain of the domain-specific roles.
+const ListDomainOutput = `
+{
+    "domains": [
+        {
+            "enabled": true,
+            "id": "1789d1",
+            "name": "domain two"
+        }
+    ]
+}
+`
+
+// ListDomainRoleOutput provides a single page with a domain-specific role.
+const ListDomainRoleOutput = `
+{
+    "roles": [
+        {
+            "domain_id": "1789d1",
+            "id": "9fe1d3",
+            "name": "support"
+        }
+    ]
+}
+`
+
+// CreateDomainRoleRequest provides the input to a Create request of a domain-specific role.
+const CreateDomainRoleRequest = `
+{
+    "role": {
+        "domain_id": "1789d1",
+        "name": "operator",
+        "description": "domain operator"
+    }
+}
+`
+
+// CreateDomainRoleOutput provides a Create result of a domain-specific role.
+const CreateDomainRoleOutput = `
+{
+    "role": {
+        "domain_id": "1789d1",
+        "id": "5ea2f7",
+        "name": "operator",
+        "extra": {
+            "description": "domain operator"
+        }
+    }
+}
+`
+
+// HandleDomainRolesSuccessfully creates HTTP handlers at `/domains` and `/roles` on
+// the test handler mux that resolve domain-specific roles of the domain `domain two`.
+func HandleDomainRolesSuccessfully(t *testing.T) {
+	th.Mux.HandleFunc("/domains", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		th.TestFormValues(t, r, map[string]string{"name": "domain two"})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, ListDomainOutput)
+	})
+	th.Mux.HandleFunc("/roles", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			if r.URL.Query().Get("domain_id") == "1789d1" && r.URL.Query().Get("name") == "support" {
+				fmt.Fprintf(w, ListDomainRoleOutput)
+			} else {
+				fmt.Fprintf(w, ListEmptyRoleOutput)
+			}
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, CreateDomainRoleRequest)
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, CreateDomainRoleOutput)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
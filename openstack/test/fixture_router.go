@@ -0,0 +1,138 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// RouterOutput is the existing router `gw-router` of project p1.
+const RouterOutput = `
+{
+    "id": "r1",
+    "name": "gw-router",
+    "description": "",
+    "project_id": "p1",
+    "admin_state_up": true,
+    "distributed": false,
+    "ha": false,
+    "external_gateway_info": {
+        "network_id": "ext1",
+        "enable_snat": true,
+        "external_fixed_ips": [{"subnet_id": "ext-s1", "ip_address": "192.0.2.10"}]
+    },
+    "routes": [{"destination": "10.2.0.0/16", "nexthop": "10.0.0.254"}]
+}
+`
+
+// ListRouterPortsOutput provides the ports of router r1: the interfaces on subnets s1 and s9 and the gateway port.
+const ListRouterPortsOutput = `
+{
+    "ports": [
+        {"id": "port1", "device_id": "r1", "device_owner": "network:router_interface", "fixed_ips": [{"subnet_id": "s1", "ip_address": "10.0.0.1"}]},
+        {"id": "port2", "device_id": "r1", "device_owner": "network:router_interface", "fixed_ips": [{"subnet_id": "s9", "ip_address": "10.9.0.1"}]},
+        {"id": "port3", "device_id": "r1", "device_owner": "network:router_gateway", "fixed_ips": [{"subnet_id": "ext-s1", "ip_address": "192.0.2.10"}]}
+    ]
+}
+`
+
+// CreateRouterRequest provides the input to a Create request.
+const CreateRouterRequest = `
+{
+    "router": {
+        "name": "new-router",
+        "description": "",
+        "project_id": "p1",
+        "external_gateway_info": {"network_id": "ext1"}
+    }
+}
+`
+
+// HandleRoutersSuccessfully creates HTTP handlers at `/routers` and `/ports` on the test handler mux.
+// The router `gw-router` (r1) of project p1 exists. The requested changes are recorded in actions.
+func HandleRoutersSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/routers", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			if r.URL.Query().Get("name") == "gw-router" && r.URL.Query().Get("project_id") == "p1" {
+				fmt.Fprintf(w, `{"routers": [%s]}`, RouterOutput)
+			} else {
+				fmt.Fprintf(w, `{"routers": []}`)
+			}
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, CreateRouterRequest)
+			*actions = append(*actions, "create router new-router")
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"router": {"id": "r2", "name": "new-router", "external_gateway_info": {"network_id": "ext1"}}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/routers/r1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "PUT")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		var body struct {
+			Router map[string]json.RawMessage `json:"router"`
+		}
+		th.AssertNoErr(t, json.NewDecoder(r.Body).Decode(&body))
+		var keys []string
+		for k, v := range body.Router {
+			keys = append(keys, fmt.Sprintf("%s=%s", k, v))
+		}
+		sort.Strings(keys)
+		*actions = append(*actions, "update router gw-router "+strings.Join(keys, " "))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"router": %s}`, RouterOutput)
+	})
+	for _, action := range []string{"add_router_interface", "remove_router_interface"} {
+		action := action
+		th.Mux.HandleFunc("/routers/r1/"+action, func(w http.ResponseWriter, r *http.Request) {
+			th.TestMethod(t, r, "PUT")
+			th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+			var body map[string]string
+			th.AssertNoErr(t, json.NewDecoder(r.Body).Decode(&body))
+			*actions = append(*actions, fmt.Sprintf("%s %s%s", action, body["port_id"], body["subnet_id"]))
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"id": "r1", "port_id": "%s", "subnet_id": "%s"}`, body["port_id"], body["subnet_id"])
+		})
+	}
+	th.Mux.HandleFunc("/ports", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		th.TestFormValues(t, r, map[string]string{"device_id": "r1"})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, ListRouterPortsOutput)
+	})
+}
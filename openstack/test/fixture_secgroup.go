@@ -0,0 +1,119 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// SecurityGroupOutput is the existing security group `bastion` of project p1 with the default egress rules,
+// ssh from 10.0.0.0/8 and icmp from its own members.
+const SecurityGroupOutput = `
+{
+    "id": "sg1",
+    "name": "bastion",
+    "description": "",
+    "project_id": "p1",
+    "security_group_rules": [
+        {"id": "rule1", "security_group_id": "sg1", "direction": "egress", "ethertype": "IPv4", "protocol": null, "port_range_min": null, "port_range_max": null, "remote_ip_prefix": null, "remote_group_id": null},
+        {"id": "rule2", "security_group_id": "sg1", "direction": "egress", "ethertype": "IPv6", "protocol": null, "port_range_min": null, "port_range_max": null, "remote_ip_prefix": null, "remote_group_id": null},
+        {"id": "rule3", "security_group_id": "sg1", "direction": "ingress", "ethertype": "IPv4", "protocol": "tcp", "port_range_min": 22, "port_range_max": 22, "remote_ip_prefix": "10.0.0.0/8", "remote_group_id": null},
+        {"id": "rule4", "security_group_id": "sg1", "direction": "ingress", "ethertype": "IPv4", "protocol": "icmp", "port_range_min": null, "port_range_max": null, "remote_ip_prefix": null, "remote_group_id": "sg1"}
+    ]
+}
+`
+
+// CreateSecurityGroupOutput is the created security group `web` with the egress rules neutron adds.
+const CreateSecurityGroupOutput = `
+{
+    "security_group": {
+        "id": "sg2",
+        "name": "web",
+        "description": "web servers",
+        "project_id": "p1",
+        "security_group_rules": [
+            {"id": "rule5", "security_group_id": "sg2", "direction": "egress", "ethertype": "IPv4"},
+            {"id": "rule6", "security_group_id": "sg2", "direction": "egress", "ethertype": "IPv6"}
+        ]
+    }
+}
+`
+
+// HandleSecurityGroupsSuccessfully creates HTTP handlers at `/security-groups` and `/security-group-rules`
+// on the test handler mux. The security group `bastion` (sg1) of project p1 exists. The requested changes
+// are recorded in actions.
+func HandleSecurityGroupsSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/security-groups", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			if r.URL.Query().Get("name") == "bastion" && r.URL.Query().Get("project_id") == "p1" {
+				fmt.Fprintf(w, `{"security_groups": [%s]}`, SecurityGroupOutput)
+			} else {
+				fmt.Fprintf(w, `{"security_groups": []}`)
+			}
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, `{"security_group": {"name": "web", "description": "web servers", "project_id": "p1"}}`)
+			*actions = append(*actions, "create security group web")
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, CreateSecurityGroupOutput)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/security-group-rules", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "POST")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		var body struct {
+			Rule struct {
+				SecGroupID     string `json:"security_group_id"`
+				Direction      string `json:"direction"`
+				EtherType      string `json:"ethertype"`
+				Protocol       string `json:"protocol"`
+				PortRangeMin   int    `json:"port_range_min"`
+				PortRangeMax   int    `json:"port_range_max"`
+				RemoteIPPrefix string `json:"remote_ip_prefix"`
+				RemoteGroupID  string `json:"remote_group_id"`
+			} `json:"security_group_rule"`
+		}
+		th.AssertNoErr(t, json.NewDecoder(r.Body).Decode(&body))
+		rule := body.Rule
+		*actions = append(*actions, fmt.Sprintf("create rule %s %s %s %s %d-%d %s%s", rule.SecGroupID, rule.Direction, rule.EtherType,
+			rule.Protocol, rule.PortRangeMin, rule.PortRangeMax, rule.RemoteIPPrefix, rule.RemoteGroupID))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"security_group_rule": {"id": "rule7", "security_group_id": "%s"}}`, rule.SecGroupID)
+	})
+	th.Mux.HandleFunc("/security-group-rules/", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "DELETE")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		*actions = append(*actions, "delete rule "+strings.TrimPrefix(r.URL.Path, "/security-group-rules/"))
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
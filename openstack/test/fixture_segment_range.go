@@ -0,0 +1,77 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// CreateNetworkSegmentRangeRequest provides the input to a Create request.
+const CreateNetworkSegmentRangeRequest = `
+{
+    "network_segment_range": {
+        "name": "hana-vlans",
+        "description": "",
+        "shared": false,
+        "project_id": "p3",
+        "network_type": "vlan",
+        "physical_network": "physnet1",
+        "minimum": 300,
+        "maximum": 399
+    }
+}
+`
+
+// HandleNetworkSegmentRangesSuccessfully creates HTTP handlers at `/network_segment_ranges` on the test handler mux.
+// The shared vlan range `tenant-vlans` (nsr1) of physnet1 exists. The requested changes are recorded in actions.
+func HandleNetworkSegmentRangesSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/network_segment_ranges", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			if r.URL.Query().Get("name") == "tenant-vlans" {
+				fmt.Fprintf(w, `{"network_segment_ranges": [{"id": "nsr1", "name": "tenant-vlans", "description": "", "default": false, "shared": true, "project_id": "", "network_type": "vlan", "physical_network": "physnet1", "minimum": 100, "maximum": 199}]}`)
+			} else {
+				fmt.Fprintf(w, `{"network_segment_ranges": []}`)
+			}
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, CreateNetworkSegmentRangeRequest)
+			*actions = append(*actions, "create network segment range hana-vlans")
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"network_segment_range": {"id": "nsr2", "name": "hana-vlans", "shared": false, "project_id": "p3", "network_type": "vlan", "physical_network": "physnet1", "minimum": 300, "maximum": 399}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/network_segment_ranges/nsr1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "PUT")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		recordUpdate(t, r, "network_segment_range", "tenant-vlans", actions)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"network_segment_range": {"id": "nsr1", "name": "tenant-vlans", "shared": true, "network_type": "vlan", "physical_network": "physnet1", "minimum": 100, "maximum": 299}}`)
+	})
+}
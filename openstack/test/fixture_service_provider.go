@@ -0,0 +1,99 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// GetServiceProviderOutput provides a Get result.
+const GetServiceProviderOutput = `
+{
+    "service_provider": {
+        "auth_url": "https://identity-3.eu-de-2.example.com/v3/OS-FEDERATION/identity_providers/eu-de-1/protocols/saml2/auth",
+        "description": "keystone of eu-de-2",
+        "enabled": true,
+        "id": "eu-de-2",
+        "relay_state_prefix": "ss:mem:",
+        "sp_url": "https://identity-3.eu-de-2.example.com/Shibboleth.sso/SAML2/ECP"
+    }
+}
+`
+
+// CreateServiceProviderRequest provides the input to a Create request.
+const CreateServiceProviderRequest = `
+{
+    "service_provider": {
+        "auth_url": "https://identity-3.eu-nl-1.example.com/v3/OS-FEDERATION/identity_providers/eu-de-1/protocols/saml2/auth",
+        "description": "keystone of eu-nl-1",
+        "enabled": true,
+        "sp_url": "https://identity-3.eu-nl-1.example.com/Shibboleth.sso/SAML2/ECP"
+    }
+}
+`
+
+// UpdateServiceProviderRequest provides the input to an Update request.
+const UpdateServiceProviderRequest = `
+{
+    "service_provider": {
+        "auth_url": "https://identity-3.eu-de-2.example.com/v3/OS-FEDERATION/identity_providers/eu-de-1/protocols/saml2/auth",
+        "description": "keystone of eu-de-2",
+        "enabled": false,
+        "sp_url": "https://identity-3.eu-de-2.example.com/Shibboleth.sso/SAML2/ECP"
+    }
+}
+`
+
+// HandleServiceProvidersSuccessfully creates HTTP handlers at `/OS-FEDERATION/service_providers`
+// on the test handler mux. `eu-nl-1` does not exist yet, `eu-de-2` exists and is enabled.
+func HandleServiceProvidersSuccessfully(t *testing.T) {
+	th.Mux.HandleFunc("/OS-FEDERATION/service_providers/eu-nl-1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+		case http.MethodPut:
+			th.TestJSONRequest(t, r, CreateServiceProviderRequest)
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"service_provider": {"id": "eu-nl-1", "enabled": true}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/OS-FEDERATION/service_providers/eu-de-2", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, GetServiceProviderOutput)
+		case http.MethodPatch:
+			th.TestJSONRequest(t, r, UpdateServiceProviderRequest)
+
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"service_provider": {"id": "eu-de-2", "enabled": false}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
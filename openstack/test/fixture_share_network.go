@@ -0,0 +1,204 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// SecurityServiceOutput is the existing security service `ad`.
+const SecurityServiceOutput = `
+{
+    "id": "ss1",
+    "name": "ad",
+    "type": "active_directory",
+    "description": "",
+    "dns_ip": "10.0.0.2",
+    "ou": "",
+    "server": "ad.example.com",
+    "domain": "example.com",
+    "user": "manila",
+    "password": "old"
+}
+`
+
+// CreateSecurityServiceRequest provides the input to a Create request.
+const CreateSecurityServiceRequest = `
+{
+    "security_service": {
+        "type": "ldap",
+        "name": "ldap",
+        "server": "ldap.example.com",
+        "user": "cn=manila",
+        "password": "secret"
+    }
+}
+`
+
+// CreateShareNetworkRequest provides the input to a Create request.
+const CreateShareNetworkRequest = `
+{
+    "share_network": {
+        "name": "nfs",
+        "description": "",
+        "neutron_net_id": "n1",
+        "neutron_subnet_id": "s1"
+    }
+}
+`
+
+// HandleSecurityServicesSuccessfully creates HTTP handlers at `/security-services` on the test handler mux.
+// The security service `ad` exists and is associated to the share network with id sn1 together with `kerberos`.
+// The requested changes are recorded in actions.
+func HandleSecurityServicesSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/security-services/detail", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		switch {
+		case r.URL.Query().Get("name") == "ad":
+			fmt.Fprintf(w, `{"security_services": [%s]}`, SecurityServiceOutput)
+		case r.URL.Query().Get("share_network_id") == "sn1":
+			fmt.Fprintf(w, `{"security_services": [%s, {"id": "ss2", "name": "kerberos", "type": "kerberos"}]}`, SecurityServiceOutput)
+		default:
+			fmt.Fprintf(w, `{"security_services": []}`)
+		}
+	})
+	th.Mux.HandleFunc("/security-services", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "POST")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		th.TestJSONRequest(t, r, CreateSecurityServiceRequest)
+		*actions = append(*actions, "create ldap")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"security_service": {"id": "ss3", "name": "ldap", "type": "ldap"}}`)
+	})
+	th.Mux.HandleFunc("/security-services/ss1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "PUT")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		th.TestJSONRequest(t, r, `{"security_service": {"name": "ad", "ou": "OU=manila", "password": "secret"}}`)
+		*actions = append(*actions, "update ad")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"security_service": %s}`, SecurityServiceOutput)
+	})
+}
+
+// HandleShareNetworksSuccessfully creates HTTP handlers at `/share-networks` on the test handler mux.
+// The share network `nfs-legacy` (sn1) exists on network n1 and subnet s2. The requested changes are recorded in actions.
+func HandleShareNetworksSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/share-networks/detail", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Query().Get("name") == "nfs-legacy" && r.URL.Query().Get("offset") == "" {
+			fmt.Fprintf(w, `{"share_networks": [{"id": "sn1", "name": "nfs-legacy", "description": "", "neutron_net_id": "n1", "neutron_subnet_id": "s2"}]}`)
+		} else {
+			fmt.Fprintf(w, `{"share_networks": []}`)
+		}
+	})
+	th.Mux.HandleFunc("/share-networks", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "POST")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		th.TestJSONRequest(t, r, CreateShareNetworkRequest)
+		*actions = append(*actions, "create nfs")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"share_network": {"id": "sn2", "name": "nfs", "neutron_net_id": "n1", "neutron_subnet_id": "s1"}}`)
+	})
+	th.Mux.HandleFunc("/share-networks/sn1/action", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "POST")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		var body map[string]struct {
+			SecurityServiceID string `json:"security_service_id"`
+		}
+		th.AssertNoErr(t, json.NewDecoder(r.Body).Decode(&body))
+		for action, opts := range body {
+			*actions = append(*actions, action+" "+opts.SecurityServiceID)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"share_network": {"id": "sn1", "name": "nfs-legacy"}}`)
+	})
+}
+
+// HandleShareQuotaSuccessfully creates HTTP handlers at `/os-quota-sets/p1` on the test handler mux.
+// The quota update request is recorded in actions.
+func HandleShareQuotaSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/os-quota-sets/p1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"quota_set": {"id": "p1", "shares": 50, "gigabytes": 1000, "snapshots": 50, "snapshot_gigabytes": 1000, "share_networks": 10}}`)
+		case http.MethodPut:
+			th.TestJSONRequest(t, r, `{"quota_set": {"gigabytes": 2048, "share_networks": 2}}`)
+			*actions = append(*actions, "update quota")
+
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"quota_set": {"shares": 50, "gigabytes": 2048, "share_networks": 2}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// HandleProjectTokensSuccessfully creates HTTP handlers at `/v3/auth/tokens` on the test handler mux.
+// The user can only authenticate in project p1, whose catalog has manila. The authentications are counted.
+func HandleProjectTokensSuccessfully(t *testing.T, authentications *int) {
+	th.Mux.HandleFunc("/v3/auth/tokens", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "POST")
+		var body struct {
+			Auth struct {
+				Scope struct {
+					Project struct {
+						ID string `json:"id"`
+					} `json:"project"`
+				} `json:"scope"`
+			} `json:"auth"`
+		}
+		th.AssertNoErr(t, json.NewDecoder(r.Body).Decode(&body))
+		*authentications++
+		if body.Auth.Scope.Project.ID != "p1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintf(w, `{"error": {"code": 401, "message": "The request you have made requires authentication.", "title": "Unauthorized"}}`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Subject-Token", client.TokenID)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token": {"expires_at": "2099-01-01T00:00:00.000000Z", "project": {"id": "p1", "name": "admin"}, "catalog": [
+			{"type": "sharev2", "name": "manilav2", "endpoints": [{"id": "e1", "interface": "public", "region": "RegionOne", "region_id": "RegionOne", "url": "%ssharev2/"}]}
+		]}}`, th.Endpoint())
+	})
+}
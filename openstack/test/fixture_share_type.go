@@ -0,0 +1,151 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// ListShareTypesOutput provides a single page of share types.
+const ListShareTypesOutput = `
+{
+    "share_types": [
+        {
+            "id": "st1",
+            "name": "default",
+            "description": "default share type",
+            "os-share-type-access:is_public": true,
+            "required_extra_specs": {
+                "driver_handles_share_servers": "True"
+            },
+            "extra_specs": {
+                "driver_handles_share_servers": "True",
+                "snapshot_support": "True",
+                "thin_provisioning": "true"
+            }
+        },
+        {
+            "id": "st2",
+            "name": "hana",
+            "description": "",
+            "os-share-type-access:is_public": false,
+            "required_extra_specs": {
+                "driver_handles_share_servers": "True"
+            },
+            "extra_specs": {
+                "driver_handles_share_servers": "True"
+            }
+        }
+    ]
+}
+`
+
+// CreateShareTypeRequest provides the input to a Create request.
+const CreateShareTypeRequest = `
+{
+    "share_type": {
+        "name": "hypervisor_storage",
+        "description": "hypervisor storage",
+        "os-share-type-access:is_public": true,
+        "extra_specs": {
+            "driver_handles_share_servers": "false"
+        }
+    }
+}
+`
+
+// HandleShareTypesSuccessfully creates HTTP handlers at `/types` on the test handler mux.
+// `default` and the private `hana` exist, `hana` can be accessed by projects `p1` and `p2`.
+// The requested changes are recorded in actions.
+func HandleShareTypesSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/types", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			th.TestFormValues(t, r, map[string]string{"is_public": "all"})
+
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, ListShareTypesOutput)
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, CreateShareTypeRequest)
+
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"share_type": {"id": "st3", "name": "hypervisor_storage"}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/types/st1/extra_specs", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"extra_specs": {"driver_handles_share_servers": "True", "snapshot_support": "True", "thin_provisioning": "true"}}`)
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, `{"extra_specs": {"snapshot_support": "false", "compression": "true"}}`)
+
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"extra_specs": {"snapshot_support": "false", "compression": "true"}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/types/st1/extra_specs/thin_provisioning", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "DELETE")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		*actions = append(*actions, "unset thin_provisioning")
+
+		w.WriteHeader(http.StatusAccepted)
+	})
+	th.Mux.HandleFunc("/types/st2/extra_specs", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"extra_specs": {"driver_handles_share_servers": "True"}}`)
+	})
+	th.Mux.HandleFunc("/types/st2/share_type_access", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"share_type_access": [{"share_type_id": "st2", "project_id": "p1"}, {"share_type_id": "st2", "project_id": "p2"}]}`)
+	})
+	th.Mux.HandleFunc("/types/st2/action", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "POST")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		var body map[string]struct {
+			Project string `json:"project"`
+		}
+		th.AssertNoErr(t, json.NewDecoder(r.Body).Decode(&body))
+		for action, opts := range body {
+			*actions = append(*actions, action+" "+opts.Project)
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
@@ -0,0 +1,152 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// SubnetPoolOutput is the existing subnet pool `pool-v4` of address scope as1.
+// Neutron has merged its prefixes 10.0.0.0/17 and 10.0.128.0/17.
+const SubnetPoolOutput = `
+{
+    "id": "sp1",
+    "name": "pool-v4",
+    "description": "",
+    "project_id": "p1",
+    "prefixes": ["10.0.0.0/16"],
+    "default_quota": null,
+    "min_prefixlen": "8",
+    "default_prefixlen": "8",
+    "max_prefixlen": "32",
+    "address_scope_id": "as1",
+    "ip_version": 4,
+    "shared": false,
+    "is_default": false
+}
+`
+
+// CreateAddressScopeRequest provides the input to a Create request.
+const CreateAddressScopeRequest = `
+{
+    "address_scope": {
+        "name": "scope-v6",
+        "project_id": "p1",
+        "ip_version": 6
+    }
+}
+`
+
+// CreateSubnetPoolRequest provides the input to a Create request.
+const CreateSubnetPoolRequest = `
+{
+    "subnetpool": {
+        "name": "pool-v6",
+        "project_id": "p1",
+        "prefixes": ["2001:db8::/48"],
+        "default_prefixlen": 64,
+        "min_prefixlen": 64,
+        "address_scope_id": "as2"
+    }
+}
+`
+
+// recordUpdate records the sorted keys and values of an update request of the resource in actions.
+func recordUpdate(t *testing.T, r *http.Request, resource, name string, actions *[]string) {
+	var body map[string]map[string]json.RawMessage
+	th.AssertNoErr(t, json.NewDecoder(r.Body).Decode(&body))
+	var keys []string
+	for k, v := range body[resource] {
+		keys = append(keys, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(keys)
+	*actions = append(*actions, fmt.Sprintf("update %s %s %s", resource, name, strings.Join(keys, " ")))
+}
+
+// HandleSubnetPoolsSuccessfully creates HTTP handlers at `/address-scopes` and `/subnetpools` on the test handler mux.
+// The address scope `scope-v4` (as1) of project p1 exists with the subnet pool `pool-v4` (sp1).
+// The requested changes are recorded in actions.
+func HandleSubnetPoolsSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/address-scopes", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			if r.URL.Query().Get("name") == "scope-v4" && r.URL.Query().Get("project_id") == "p1" {
+				fmt.Fprintf(w, `{"address_scopes": [{"id": "as1", "name": "scope-v4", "project_id": "p1", "ip_version": 4, "shared": false}]}`)
+			} else {
+				fmt.Fprintf(w, `{"address_scopes": []}`)
+			}
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, CreateAddressScopeRequest)
+			*actions = append(*actions, "create address scope scope-v6")
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"address_scope": {"id": "as2", "name": "scope-v6", "project_id": "p1", "ip_version": 6}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/address-scopes/as1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "PUT")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		recordUpdate(t, r, "address_scope", "scope-v4", actions)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"address_scope": {"id": "as1", "name": "scope-v4", "project_id": "p1", "ip_version": 4, "shared": true}}`)
+	})
+	th.Mux.HandleFunc("/subnetpools", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			if r.URL.Query().Get("name") == "pool-v4" && r.URL.Query().Get("project_id") == "p1" {
+				fmt.Fprintf(w, `{"subnetpools": [%s]}`, SubnetPoolOutput)
+			} else {
+				fmt.Fprintf(w, `{"subnetpools": []}`)
+			}
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, CreateSubnetPoolRequest)
+			*actions = append(*actions, "create subnet pool pool-v6")
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"subnetpool": {"id": "sp2", "name": "pool-v6", "prefixes": ["2001:db8::/48"], "min_prefixlen": 64, "default_prefixlen": 64, "max_prefixlen": 128, "address_scope_id": "as2"}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/subnetpools/sp1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "PUT")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		recordUpdate(t, r, "subnetpool", "pool-v4", actions)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"subnetpool": %s}`, SubnetPoolOutput)
+	})
+}
@@ -0,0 +1,243 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+// ListVolumeTypesOutput provides a single page of volume types.
+const ListVolumeTypesOutput = `
+{
+    "volume_types": [
+        {
+            "id": "vt1",
+            "name": "vmware",
+            "description": "vmware volumes",
+            "is_public": true,
+            "qos_specs_id": "q1",
+            "extra_specs": {
+                "volume_backend_name": "vmware",
+                "vmware:storage_profile": "gold"
+            }
+        },
+        {
+            "id": "vt2",
+            "name": "hana",
+            "description": "hana volumes",
+            "is_public": false,
+            "extra_specs": {}
+        }
+    ]
+}
+`
+
+// CreateVolumeTypeRequest provides the input to a Create request.
+const CreateVolumeTypeRequest = `
+{
+    "volume_type": {
+        "name": "netapp",
+        "extra_specs": {
+            "volume_backend_name": "netapp"
+        }
+    }
+}
+`
+
+// HandleVolumeTypesSuccessfully creates HTTP handlers at `/types` on the test handler mux.
+// `vmware` and the private `hana` exist, `hana` can be accessed by projects `p1` and `p2`.
+// The requested changes are recorded in actions.
+func HandleVolumeTypesSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/types", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			th.TestFormValues(t, r, map[string]string{"is_public": "None"})
+
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, ListVolumeTypesOutput)
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, CreateVolumeTypeRequest)
+
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"volume_type": {"id": "vt3", "name": "netapp", "is_public": true}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/types/vt1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "PUT")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		th.TestJSONRequest(t, r, `{"volume_type": {"description": "vmware vvol volumes"}}`)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"volume_type": {"id": "vt1", "name": "vmware", "description": "vmware vvol volumes", "is_public": true}}`)
+	})
+	th.Mux.HandleFunc("/types/vt1/extra_specs", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"extra_specs": {"volume_backend_name": "vmware", "vmware:storage_profile": "gold"}}`)
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, `{"extra_specs": {"vmware:storage_profile": "vvol"}}`)
+
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"extra_specs": {"vmware:storage_profile": "vvol"}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/types/vt2/extra_specs", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"extra_specs": {}}`)
+	})
+	th.Mux.HandleFunc("/types/vt2/os-volume-type-access", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"volume_type_access": [{"volume_type_id": "vt2", "project_id": "p1"}, {"volume_type_id": "vt2", "project_id": "p2"}]}`)
+	})
+	th.Mux.HandleFunc("/types/vt2/action", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "POST")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		var body map[string]struct {
+			Project string `json:"project"`
+		}
+		th.AssertNoErr(t, json.NewDecoder(r.Body).Decode(&body))
+		for action, opts := range body {
+			*actions = append(*actions, action+" "+opts.Project)
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
+
+// HandleVolumeTypeEncryptionSuccessfully creates HTTP handlers at `/types/vt1/encryption` on the
+// test handler mux. Volume type `vt1` is encrypted and in use.
+func HandleVolumeTypeEncryptionSuccessfully(t *testing.T) {
+	th.Mux.HandleFunc("/types/vt1/encryption", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"volume_type_id": "vt1", "encryption_id": "e1", "provider": "luks", "cipher": "aes-xts-plain64", "key_size": 256, "control_location": "front-end"}`)
+	})
+	th.Mux.HandleFunc("/types/vt1/encryption/e1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "PUT")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		th.TestJSONRequest(t, r, `{"encryption": {"provider": "luks", "cipher": "aes-xts-plain64", "key_size": 512, "control_location": "front-end"}}`)
+
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"badRequest": {"message": "Cannot update encryption specs. Volume type in use.", "code": 400}}`)
+	})
+}
+
+// ListQosSpecsOutput provides a single page of QoS specs.
+const ListQosSpecsOutput = `
+{
+    "qos_specs": [
+        {
+            "id": "q1",
+            "name": "gold",
+            "consumer": "back-end",
+            "specs": {
+                "read_iops_sec": "2000",
+                "total_iops_sec": "2000"
+            }
+        }
+    ]
+}
+`
+
+// HandleQosSpecsSuccessfully creates HTTP handlers at `/qos-specs` on the test handler mux.
+// QoS spec `gold` exists and is associated with volume types `vt1` and `vt9`.
+// The requested changes are recorded in actions.
+func HandleQosSpecsSuccessfully(t *testing.T, actions *[]string) {
+	th.Mux.HandleFunc("/qos-specs", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, ListQosSpecsOutput)
+		case http.MethodPost:
+			th.TestJSONRequest(t, r, `{"qos_specs": {"name": "silver", "consumer": "back-end", "total_iops_sec": "1000"}}`)
+
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"qos_specs": {"id": "q2", "name": "silver", "consumer": "back-end", "specs": {"total_iops_sec": "1000"}}}`)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	th.Mux.HandleFunc("/qos-specs/q1/associations", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"qos_associations": [{"association_type": "volume_type", "name": "vmware", "id": "vt1"}, {"association_type": "volume_type", "name": "old", "id": "vt9"}]}`)
+	})
+	th.Mux.HandleFunc("/qos-specs/q2/associations", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"qos_associations": []}`)
+	})
+	th.Mux.HandleFunc("/qos-specs/q1/", func(w http.ResponseWriter, r *http.Request) {
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		action := r.Method + " " + r.URL.Path[len("/qos-specs/"):]
+		if r.Method == http.MethodGet {
+			action += " " + r.URL.Query().Get("vol_type_id")
+		} else {
+			b, _ := ioutil.ReadAll(r.Body)
+			action += " " + string(b)
+		}
+		*actions = append(*actions, action)
+
+		w.WriteHeader(http.StatusAccepted)
+	})
+	th.Mux.HandleFunc("/qos-specs/q1", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "PUT")
+		th.TestHeader(t, r, "X-Auth-Token", client.TokenID)
+		b, _ := ioutil.ReadAll(r.Body)
+		*actions = append(*actions, "PUT q1 "+string(b))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"qos_specs": {"consumer": "front-end", "total_iops_sec": "5000"}}`)
+	})
+}
@@ -55,6 +55,44 @@ func TestSeedService(t *testing.T) {
 	assert.NotNil(t, upd)
 }
 
+func TestSeedEndpoints(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleEndpointsSuccessfully(t, &actions)
+
+	kc := openstack.NewKeystone(client.ServiceClient())
+	e, err := kc.SeedEndpoints(openstackstablesapccv2.EndpointSpec{
+		Region: "eu-de-1", Interface: "public", URL: "https://nova.example.com/v2.1", Enabled: true,
+	}, "9876")
+	assert.NoError(t, err, "url and enabled flag should be updated")
+	assert.Equal(t, "e1", e.ID)
+
+	e, err = kc.SeedEndpoints(openstackstablesapccv2.EndpointSpec{
+		Region: "eu-de-1", Interface: "internal", URL: "http://nova.internal:8774/v2.1", Enabled: true,
+	}, "9876")
+	assert.NoError(t, err, "endpoints of other interfaces should be created")
+	assert.Equal(t, "e2", e.ID)
+
+	e, err = kc.SeedEndpoints(openstackstablesapccv2.EndpointSpec{
+		Region: "eu-de-1", Interface: "public", URL: "https://nova.example.com:8774/v2.1", Enabled: false,
+	}, "9876")
+	assert.NoError(t, err)
+	assert.Nil(t, e, "unchanged endpoints should not be updated")
+	assert.Equal(t, []string{"update public endpoint e1", "create internal endpoint"}, actions)
+}
+
+func TestGetServiceID(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	HandleListServicesSuccessfully(t)
+
+	kc := openstack.NewKeystone(client.ServiceClient())
+	id, err := kc.GetServiceID("compute", "service-two")
+	assert.NoError(t, err)
+	assert.Equal(t, "9876", id)
+}
+
 func TestSeedDomain(t *testing.T) {
 	specEqual := openstackstablesapccv2.DomainSpec{
 		Name:        "domain one",
@@ -124,3 +162,228 @@ func TestSeedRoleNotExist(t *testing.T) {
 	assert.Equal(t, upd.Name, specNotExist.Name)
 	assert.Equal(t, upd.Extra["description"], specNotExist.Description)
 }
+
+func TestSeedRegion(t *testing.T) {
+	specNotExist := openstackstablesapccv2.RegionSpec{
+		ID:          "eu",
+		Description: "europe",
+	}
+	specEqual := openstackstablesapccv2.RegionSpec{
+		ID:             "eu-de-1",
+		Description:    "some description",
+		ParentRegionID: "eu",
+	}
+	specParentRemoved := openstackstablesapccv2.RegionSpec{
+		ID:          "eu-de-1",
+		Description: "some description",
+	}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	HandleRegionsSuccessfully(t)
+
+	kc := openstack.Keystone{Client: client.ServiceClient()}
+	upd, err := kc.SeedRegion(specNotExist)
+	assert.NoError(t, err, "region should be created")
+	assert.Equal(t, specNotExist.ID, upd.ID)
+
+	upd, err = kc.SeedRegion(specEqual)
+	assert.NoError(t, err, "error should be nil")
+	assert.Nil(t, upd, "patch should not be called")
+
+	upd, err = kc.SeedRegion(specParentRemoved)
+	assert.NoError(t, err, "patch should be called")
+	assert.Equal(t, "", upd.ParentRegionID)
+}
+
+func TestSeedRegionsCycle(t *testing.T) {
+	specs := []openstackstablesapccv2.RegionSpec{
+		{ID: "eu", ParentRegionID: "eu-de-1"},
+		{ID: "eu-de-1", ParentRegionID: "eu-de"},
+		{ID: "eu-de", ParentRegionID: "eu"},
+	}
+	kc := openstack.Keystone{}
+	err := kc.SeedRegions(specs)
+	assert.EqualError(t, err, "region parent cycle: eu -> eu-de-1 -> eu-de -> eu")
+}
+
+func TestSeedDomainRole(t *testing.T) {
+	spec := openstackstablesapccv2.RoleSpec{
+		Name:        "operator",
+		Domain:      "domain two",
+		Description: "domain operator",
+	}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	HandleDomainRolesSuccessfully(t)
+
+	kc := openstack.NewKeystone(client.ServiceClient())
+	upd, err := kc.SeedRole(spec)
+	assert.NoError(t, err, "error should be nil")
+	assert.Equal(t, "1789d1", upd.DomainID)
+}
+
+func TestGetRoleID(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	HandleDomainRolesSuccessfully(t)
+
+	kc := openstack.NewKeystone(client.ServiceClient())
+	id, err := kc.GetRoleID("support@domain two")
+	assert.NoError(t, err, "domain role should be found")
+	assert.Equal(t, "9fe1d3", id)
+
+	_, err = kc.GetRoleID("support")
+	assert.Error(t, err, "global role should not be found")
+
+	_, err = kc.GetRoleID("support@domain@two")
+	assert.EqualError(t, err, "role name wrong format: role@domain")
+}
+
+func TestGetProjectID(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	HandleProjectLookupSuccessfully(t)
+
+	kc := openstack.NewKeystone(client.ServiceClient())
+	id, err := kc.GetProjectID("monsoon3", "admin")
+	assert.NoError(t, err, "project should be found")
+	assert.Equal(t, "p1", id)
+
+	_, err = kc.GetProjectID("monsoon3", "unknown")
+	assert.EqualError(t, err, "could not find project: unknown")
+	assert.True(t, openstack.IsNotFound(err))
+}
+
+func TestGetTokenProjectID(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	HandleTokenSuccessfully(t)
+
+	kc := openstack.NewKeystone(client.ServiceClient())
+	id, err := kc.GetTokenProjectID()
+	assert.NoError(t, err)
+	assert.Equal(t, "p9", id)
+}
+
+func TestGetProjectIDFailure(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	HandleDomainLookupFailure(t)
+
+	kc := openstack.NewKeystone(client.ServiceClient())
+	_, err := kc.GetProjectID("monsoon3", "admin")
+	assert.Error(t, err)
+	assert.False(t, openstack.IsNotFound(err), "failing lookups should not be taken for missing projects")
+}
+
+func TestSeedServiceProvider(t *testing.T) {
+	enabled, disabled := true, false
+	specNotExist := openstackstablesapccv2.ServiceProviderSpec{
+		ID:          "eu-nl-1",
+		Description: "keystone of eu-nl-1",
+		AuthURL:     "https://identity-3.eu-nl-1.example.com/v3/OS-FEDERATION/identity_providers/eu-de-1/protocols/saml2/auth",
+		SPURL:       "https://identity-3.eu-nl-1.example.com/Shibboleth.sso/SAML2/ECP",
+		Enabled:     &enabled,
+	}
+	specEqual := openstackstablesapccv2.ServiceProviderSpec{
+		ID:          "eu-de-2",
+		Description: "keystone of eu-de-2",
+		AuthURL:     "https://identity-3.eu-de-2.example.com/v3/OS-FEDERATION/identity_providers/eu-de-1/protocols/saml2/auth",
+		SPURL:       "https://identity-3.eu-de-2.example.com/Shibboleth.sso/SAML2/ECP",
+	}
+	specNotEqual := specEqual
+	specNotEqual.Enabled = &disabled
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	HandleServiceProvidersSuccessfully(t)
+
+	kc := openstack.Keystone{Client: client.ServiceClient()}
+	upd, err := kc.SeedServiceProvider(specNotExist)
+	assert.NoError(t, err, "service provider should be created")
+	assert.Equal(t, "eu-nl-1", upd.ID)
+
+	upd, err = kc.SeedServiceProvider(specEqual)
+	assert.NoError(t, err, "error should be nil")
+	assert.Nil(t, upd, "patch should not be called")
+
+	upd, err = kc.SeedServiceProvider(specNotEqual)
+	assert.NoError(t, err, "patch should be called")
+	assert.False(t, upd.Enabled)
+
+	specNotEqual.AuthURL = "identity-3"
+	_, err = kc.SeedServiceProvider(specNotEqual)
+	assert.Error(t, err, "invalid auth url should be rejected")
+}
+
+func TestSeedCredential(t *testing.T) {
+	userID := "bb5476fd12884539b41d5a88f838d773"
+	totp := openstackstablesapccv2.CredentialSpec{Type: "totp"}
+	cert := openstackstablesapccv2.CredentialSpec{Type: "cert"}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	HandleCredentialsSuccessfully(t)
+
+	kc := openstack.Keystone{Client: client.ServiceClient()}
+	upd, err := kc.SeedCredential(userID, totp, "GEZDGNBVGY3TQOJQ")
+	assert.NoError(t, err, "error should be nil")
+	assert.Nil(t, upd, "patch should not be called")
+
+	upd, err = kc.SeedCredential(userID, totp, "MFRGGZDFMZTWQ2LK")
+	assert.NoError(t, err, "patch should be called")
+	assert.NotNil(t, upd)
+
+	upd, err = kc.SeedCredential(userID, cert, "-----BEGIN CERTIFICATE-----")
+	assert.NoError(t, err, "credential should be created")
+	assert.NotNil(t, upd)
+}
+
+func TestSeedEC2Credential(t *testing.T) {
+	userID := "bb5476fd12884539b41d5a88f838d773"
+	ec2 := openstackstablesapccv2.CredentialSpec{Type: "ec2"}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleEC2CredentialsSuccessfully(t, &actions)
+
+	kc := openstack.Keystone{Client: client.ServiceClient()}
+	_, err := kc.SeedCredential(userID, ec2, `{"access": "seeded", "secret": "seededSecret"}`)
+	assert.NoError(t, err, "credential should be created")
+	_, err = kc.SeedCredential(userID, ec2, `{"access": "181920", "secret": "rotatedSecret"}`)
+	assert.NoError(t, err, "credential should be updated")
+	assert.Equal(t, []string{"create seeded", "update 181920"}, actions, "the ec2 credentials of the user should be kept")
+
+	_, err = kc.SeedCredential(userID, ec2, "secretKey")
+	assert.Error(t, err, "ec2 credentials need an access key")
+	assert.NotContains(t, err.Error(), "secretKey")
+}
+
+func TestAuditCatalog(t *testing.T) {
+	declared := []openstackstablesapccv2.ServiceSpec{
+		{
+			Type: "compute",
+			Name: "nova",
+			Endpoints: []openstackstablesapccv2.EndpointSpec{
+				{Region: "eu-de-1", Interface: "public", URL: "https://compute-3.eu-de-1.example.com/v2.1"},
+				{Region: "eu-de-1", Interface: "internal", URL: "http://nova-api.monsoon3:8774/v2.1"},
+				{Region: "eu-de-2", Interface: "public", URL: "https://compute-3.eu-de-2.example.com:443/v2.1"},
+			},
+		},
+	}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	HandleListCatalogSuccessfully(t)
+
+	kc := openstack.Keystone{Client: client.ServiceClient()}
+	findings, err := kc.AuditCatalog(declared)
+	assert.NoError(t, err, "error should be nil")
+	kinds := make([]string, 0, len(findings))
+	for _, f := range findings {
+		kinds = append(kinds, f.Kind+" "+f.Service+" "+f.Region+" "+f.Interface)
+	}
+	assert.Equal(t, []string{
+		"DuplicateEndpoint compute/nova eu-de-1 internal",
+		"UndeclaredEndpoint compute/nova eu-de-2 public",
+		"UndeclaredEndpoint image/glance eu-de-1 public",
+		"UndeclaredService image/glance  ",
+	}, kinds)
+}
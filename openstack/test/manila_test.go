@@ -0,0 +1,229 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"testing"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+	"github.com/sapcc/openstack-seeder/openstack"
+	"github.com/stretchr/testify/assert"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+func TestSeedShareType(t *testing.T) {
+	yes, no := true, false
+	spec := openstackstablesapccv2.ShareTypeSpec{
+		Name:        "default",
+		Description: "default share type",
+		Specs:       &openstackstablesapccv2.ShareTypeSpecifiedSpecs{DHSS: &yes, SnapshotSupport: &no},
+		ExtraSpecs:  map[string]string{"compression": "true"},
+	}
+	specNotExist := openstackstablesapccv2.ShareTypeSpec{
+		Name:        "hypervisor_storage",
+		Description: "hypervisor storage",
+		Specs:       &openstackstablesapccv2.ShareTypeSpecifiedSpecs{DHSS: &no},
+	}
+	specDrift := openstackstablesapccv2.ShareTypeSpec{
+		Name:     "hana",
+		IsPublic: &yes,
+		Specs:    &openstackstablesapccv2.ShareTypeSpecifiedSpecs{DHSS: &yes},
+	}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleShareTypesSuccessfully(t, &actions)
+
+	m := openstack.NewManila(client.ServiceClient())
+	id, drift, err := m.SeedShareType(spec)
+	assert.NoError(t, err, "extra specs should be updated")
+	assert.Equal(t, "st1", id)
+	assert.Empty(t, drift)
+	assert.Equal(t, []string{"unset thin_provisioning"}, actions)
+
+	id, _, err = m.SeedShareType(specNotExist)
+	assert.NoError(t, err, "share type should be created")
+	assert.Equal(t, "st3", id)
+
+	_, drift, err = m.SeedShareType(specDrift)
+	assert.NoError(t, err, "drift should not fail the seed")
+	if assert.Len(t, drift, 1) {
+		assert.Equal(t, "share type hana: is_public is false instead of true", drift[0].String())
+	}
+}
+
+func TestValidateShareType(t *testing.T) {
+	yes := true
+	spec := openstackstablesapccv2.ShareTypeSpec{
+		Name:  "hana",
+		Specs: &openstackstablesapccv2.ShareTypeSpecifiedSpecs{DHSS: &yes},
+	}
+	assert.NoError(t, openstack.ValidateShareType(spec))
+
+	spec.ExtraSpecs = map[string]string{"driver_handles_share_servers": "true"}
+	assert.EqualError(t, openstack.ValidateShareType(spec),
+		"share type hana: driver_handles_share_servers must be declared in specs, not in extra_specs")
+
+	spec.Specs = nil
+	assert.EqualError(t, openstack.ValidateShareType(spec), "share type hana: specs.driver_handles_share_servers is required")
+}
+
+func TestSeedShareTypeAccess(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleShareTypesSuccessfully(t, &actions)
+
+	m := openstack.NewManila(client.ServiceClient())
+	assert.NoError(t, m.SeedShareTypeAccess("default", []string{"p1"}, true), "public share types should be skipped")
+
+	assert.NoError(t, m.SeedShareTypeAccess("hana", []string{"p1", "p3"}, false), "access should be granted")
+	assert.Equal(t, []string{"addProjectAccess p3"}, actions)
+
+	actions = nil
+	assert.NoError(t, m.SeedShareTypeAccess("hana", []string{"p1", "p3"}, true), "access should be granted and revoked")
+	assert.Equal(t, []string{"addProjectAccess p3", "removeProjectAccess p2"}, actions)
+
+	assert.Error(t, m.SeedShareTypeAccess("unknown", nil, false), "unknown share types should be rejected")
+}
+
+func TestSeedSecurityService(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleSecurityServicesSuccessfully(t, &actions)
+
+	m := openstack.NewManila(client.ServiceClient())
+	id, drift, err := m.SeedSecurityService(openstackstablesapccv2.SecurityServiceSpec{
+		Name: "ad", Type: "active_directory", DNSIP: "10.0.0.2", OU: "OU=manila",
+		Server: "ad.example.com", Domain: "example.com", User: "manila",
+	}, "secret")
+	assert.NoError(t, err, "security service should be updated")
+	assert.Equal(t, "ss1", id)
+	assert.Empty(t, drift)
+
+	_, drift, err = m.SeedSecurityService(openstackstablesapccv2.SecurityServiceSpec{
+		Name: "ad", Type: "ldap", DNSIP: "10.0.0.2", Server: "ad.example.com", Domain: "example.com", User: "manila",
+	}, "")
+	assert.NoError(t, err, "drift should not fail the seed")
+	if assert.Len(t, drift, 1) {
+		assert.Equal(t, "security service ad: type is active_directory instead of ldap", drift[0].String())
+	}
+
+	id, _, err = m.SeedSecurityService(openstackstablesapccv2.SecurityServiceSpec{
+		Name: "ldap", Type: "ldap", Server: "ldap.example.com", User: "cn=manila",
+	}, "secret")
+	assert.NoError(t, err, "security service should be created")
+	assert.Equal(t, "ss3", id)
+	assert.Equal(t, []string{"update ad", "create ldap"}, actions)
+}
+
+func TestValidateSecurityService(t *testing.T) {
+	assert.NoError(t, openstack.ValidateSecurityService(openstackstablesapccv2.SecurityServiceSpec{Name: "ad", Type: "active_directory"}))
+	assert.EqualError(t, openstack.ValidateSecurityService(openstackstablesapccv2.SecurityServiceSpec{Name: "nis", Type: "nis"}),
+		"security service nis: invalid type nis: must be active_directory, kerberos or ldap")
+}
+
+func TestSeedShareNetwork(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleSecurityServicesSuccessfully(t, &actions)
+	HandleShareNetworksSuccessfully(t, &actions)
+	HandleNetworkLookupSuccessfully(t)
+	HandleProjectLookupSuccessfully(t)
+
+	n := openstack.NewNeutron(client.ServiceClient(), openstack.NewKeystone(client.ServiceClient()))
+	networkID, err := n.GetNetworkID("private", "p1")
+	assert.NoError(t, err)
+	assert.Equal(t, "n1", networkID)
+	subnetID, err := n.GetSubnetID("private-v4", "p1")
+	assert.NoError(t, err)
+	assert.Equal(t, "s1", subnetID)
+	id, err := n.GetSubnetID("storage-v4@storage@monsoon3", "p1")
+	assert.NoError(t, err, "subnets of other projects should be resolved")
+	assert.Equal(t, "s2", id)
+	_, err = n.GetNetworkID("storage", "p1")
+	assert.Error(t, err, "networks of other projects need a name@project@domain reference")
+	_, err = n.GetNetworkID("storage@storage", "p1")
+	assert.Error(t, err, "malformed references should be rejected")
+
+	m := openstack.NewManila(client.ServiceClient())
+	id, drift, err := m.SeedShareNetwork(openstackstablesapccv2.ShareNetworkSpec{Name: "nfs", Network: "private", Subnet: "private-v4"}, networkID, subnetID)
+	assert.NoError(t, err, "share network should be created")
+	assert.Equal(t, "sn2", id)
+	assert.Empty(t, drift)
+
+	id, drift, err = m.SeedShareNetwork(openstackstablesapccv2.ShareNetworkSpec{
+		Name: "nfs-legacy", Network: "private", Subnet: "private-v4", SecurityServices: []string{"ad"},
+	}, networkID, subnetID)
+	assert.NoError(t, err, "drift should not fail the seed")
+	assert.Equal(t, "sn1", id)
+	if assert.Len(t, drift, 1) {
+		assert.Equal(t, "share network nfs-legacy: neutron_subnet_id is s2 instead of s1", drift[0].String())
+	}
+	assert.Equal(t, []string{"create nfs", "remove_security_service ss2"}, actions)
+
+	_, _, err = m.SeedShareNetwork(openstackstablesapccv2.ShareNetworkSpec{Name: "nfs", SecurityServices: []string{"ldap"}}, networkID, subnetID)
+	assert.Error(t, err, "unknown security services should be rejected")
+}
+
+func TestSeedShareQuota(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleShareQuotaSuccessfully(t, &actions)
+
+	m := openstack.NewManila(client.ServiceClient())
+	changes, err := m.SeedShareQuota("p1", "admin@monsoon3", openstackstablesapccv2.ShareQuotaSpec{
+		Shares: 50, Gigabytes: 2048, ShareNetworks: 2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"update quota"}, actions)
+	if assert.Len(t, changes, 2) {
+		assert.Equal(t, "gigabytes", changes[0].Resource)
+		assert.Equal(t, int64(1000), changes[0].Old)
+		assert.Equal(t, int64(2048), changes[0].New)
+		assert.Equal(t, "share_networks", changes[1].Resource)
+	}
+}
+
+func TestNewProjectSharedFileSystemClient(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var authentications int
+	HandleProjectTokensSuccessfully(t, &authentications)
+	t.Setenv("OS_AUTH_URL", th.Endpoint()+"v3/")
+	t.Setenv("OS_USERNAME", "seeder")
+	t.Setenv("OS_PASSWORD", "secret")
+	t.Setenv("OS_DOMAIN_NAME", "Default")
+	t.Setenv("OS_REGION_NAME", "RegionOne")
+
+	c, err := openstack.NewProjectSharedFileSystemClient("p1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, th.Endpoint()+"sharev2/", c.Endpoint)
+	}
+	_, err = openstack.NewProjectSharedFileSystemClient("p1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, authentications, "the provider of a project should be cached")
+
+	_, err = openstack.NewProjectSharedFileSystemClient("p2")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "needs the member role", "the error should name the missing role")
+}
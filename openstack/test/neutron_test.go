@@ -0,0 +1,639 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"testing"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+	"github.com/sapcc/openstack-seeder/openstack"
+	"github.com/stretchr/testify/assert"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+func TestSeedNetwork(t *testing.T) {
+	yes, no := true, false
+	spec := openstackstablesapccv2.NetworkSpec{
+		Name:                    "private",
+		Shared:                  &yes,
+		AdminStateUp:            &yes,
+		ProviderNetworkType:     "vlan",
+		ProviderPhysicalNetwork: "physnet1",
+		ProviderSegmentationId:  "200",
+		Tags:                    []string{"b", "a"},
+		Subnets: []openstackstablesapccv2.SubnetSpec{
+			{
+				Name:            "private-v4",
+				CIDR:            "10.0.0.0/23",
+				EnableDHCP:      &yes,
+				DNSNameServers:  []string{"10.0.0.2", "10.0.0.3"},
+				AllocationPools: []string{"10.0.0.200-10.0.0.250", "10.0.0.10-10.0.0.100"},
+				HostRoutes:      []string{},
+			},
+		},
+	}
+	specNotExist := openstackstablesapccv2.NetworkSpec{
+		Name:                    "public",
+		RouterExternal:          &yes,
+		ProviderNetworkType:     "flat",
+		ProviderPhysicalNetwork: "physnet2",
+		Subnets: []openstackstablesapccv2.SubnetSpec{
+			{
+				Name:       "public-v4",
+				CIDR:       "192.168.0.0/24",
+				EnableDHCP: &no,
+				HostRoutes: []string{"10.1.0.0/16,192.168.0.254"},
+			},
+		},
+	}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleNetworksSuccessfully(t, &actions)
+
+	n := openstack.NewNeutron(client.ServiceClient(), nil)
+	network, drift, err := n.SeedNetwork("p1", spec)
+	assert.NoError(t, err, "drift should not fail the seed")
+	assert.Equal(t, "n1", network.ID)
+	if assert.Len(t, drift, 2) {
+		assert.Equal(t, "network private: provider_segmentation_id is 100 instead of 200", drift[0].String())
+		assert.Equal(t, "subnet private-v4: cidr is 10.0.0.0/24 instead of 10.0.0.0/23", drift[1].String())
+	}
+	assert.Equal(t, []string{"update network private", "tag network private [b a]", "update subnet private-v4"}, actions)
+
+	actions = nil
+	network, drift, err = n.SeedNetwork("p1", specNotExist)
+	assert.NoError(t, err, "network should be created")
+	assert.Equal(t, "n3", network.ID)
+	assert.Empty(t, drift)
+	assert.Equal(t, []string{"create network public", "create subnet public-v4"}, actions)
+}
+
+func TestValidateNetwork(t *testing.T) {
+	spec := openstackstablesapccv2.NetworkSpec{
+		Name:                   "public",
+		ProviderSegmentationId: "100",
+		Subnets:                []openstackstablesapccv2.SubnetSpec{{Name: "public-v4", CIDR: "10.1.0.0/16"}},
+	}
+	assert.NoError(t, openstack.ValidateNetwork(spec))
+
+	spec.ProviderSegmentationId = "vlan100"
+	assert.Error(t, openstack.ValidateNetwork(spec), "invalid segmentation ids should be rejected")
+
+	spec.ProviderSegmentationId = ""
+	spec.Subnets[0].HostRoutes = []string{"10.1.0.0/16"}
+	assert.Error(t, openstack.ValidateNetwork(spec), "invalid host routes should be rejected")
+}
+
+func TestValidateSubnet(t *testing.T) {
+	valid := openstackstablesapccv2.SubnetSpec{
+		Name:            "private-v6",
+		CIDR:            "2001:db8::/64",
+		GatewayIP:       "2001:db8::1",
+		AllocationPools: []string{"2001:db8::10-2001:db8::ff"},
+		HostRoutes:      []string{"2001:db8:1::/48,2001:db8::fe"},
+	}
+	assert.NoError(t, openstack.ValidateSubnet(valid))
+	assert.NoError(t, openstack.ValidateSubnet(openstackstablesapccv2.SubnetSpec{
+		Name:            "from-pool",
+		AllocationPools: []string{"10.0.0.10-10.0.0.100"},
+	}), "addresses of subnets without cidr cannot be checked")
+
+	for msg, modify := range map[string]func(*openstackstablesapccv2.SubnetSpec){
+		"invalid cidr":         func(s *openstackstablesapccv2.SubnetSpec) { s.CIDR = "2001:db8::/129" },
+		"gateway outside cidr": func(s *openstackstablesapccv2.SubnetSpec) { s.GatewayIP = "2001:db9::1" },
+		"pool without end":     func(s *openstackstablesapccv2.SubnetSpec) { s.AllocationPools = []string{"2001:db8::10"} },
+		"pool outside cidr": func(s *openstackstablesapccv2.SubnetSpec) {
+			s.AllocationPools = []string{"2001:db8::10-2001:db8:0:1::ff"}
+		},
+		"pool start after end":   func(s *openstackstablesapccv2.SubnetSpec) { s.AllocationPools = []string{"2001:db8::ff-2001:db8::10"} },
+		"route without nexthop":  func(s *openstackstablesapccv2.SubnetSpec) { s.HostRoutes = []string{"2001:db8:1::/48"} },
+		"route destination":      func(s *openstackstablesapccv2.SubnetSpec) { s.HostRoutes = []string{"2001:db8:1::,2001:db8::fe"} },
+		"nexthop outside cidr":   func(s *openstackstablesapccv2.SubnetSpec) { s.HostRoutes = []string{"2001:db8:1::/48,2001:db8:1::1"} },
+		"nexthop not an address": func(s *openstackstablesapccv2.SubnetSpec) { s.HostRoutes = []string{"2001:db8:1::/48,router"} },
+	} {
+		spec := valid
+		modify(&spec)
+		assert.Error(t, openstack.ValidateSubnet(spec), msg)
+	}
+}
+
+func TestSeedRouter(t *testing.T) {
+	yes, no := true, false
+	spec := openstackstablesapccv2.RouterSpec{
+		Name:        "gw-router",
+		Distributed: &yes,
+		ExternalGatewayInfo: &openstackstablesapccv2.ExternalGatewayInfoSpec{
+			Network:          "ext@admin@monsoon3",
+			EnableSNAT:       &no,
+			ExternalFixedIPs: []openstackstablesapccv2.ExternalFixedIPsSpec{{Subnet: "ext-v4"}},
+		},
+		RouterPorts: []openstackstablesapccv2.RouterPortSpec{{Subnet: "private-v4"}, {PortId: "port4"}},
+		Routes: []openstackstablesapccv2.RouterRouteSpec{
+			{Destination: "10.3.0.0/16", Nexthop: "10.0.0.254"},
+			{Destination: "10.2.0.0/16", Nexthop: "10.0.0.254"},
+		},
+	}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleRoutersSuccessfully(t, &actions)
+	HandleNetworkLookupSuccessfully(t)
+	HandleProjectLookupSuccessfully(t)
+
+	n := openstack.NewNeutron(client.ServiceClient(), openstack.NewKeystone(client.ServiceClient()))
+	router, drift, err := n.SeedRouter("p1", spec)
+	assert.NoError(t, err, "drift should not fail the seed")
+	assert.Equal(t, "r1", router.ID)
+	if assert.Len(t, drift, 1) {
+		assert.Equal(t, "router gw-router: distributed is false instead of true", drift[0].String())
+	}
+	assert.Equal(t, []string{
+		`update router gw-router external_gateway_info={"enable_snat":false,"external_fixed_ips":[{"subnet_id":"ext-s1"}],"network_id":"ext1"}`,
+		"remove_router_interface port2",
+		"add_router_interface port4",
+		`update router gw-router routes=[{"destination":"10.3.0.0/16","nexthop":"10.0.0.254"},{"destination":"10.2.0.0/16","nexthop":"10.0.0.254"}]`,
+	}, actions)
+
+	actions = nil
+	spec.Distributed = nil
+	spec.ExternalGatewayInfo = &openstackstablesapccv2.ExternalGatewayInfoSpec{
+		Network:          "ext",
+		ExternalFixedIPs: []openstackstablesapccv2.ExternalFixedIPsSpec{{SubnetId: "ext-s1", IpAddress: "192.0.2.10"}},
+	}
+	spec.RouterPorts = []openstackstablesapccv2.RouterPortSpec{{SubnetId: "s1"}, {PortId: "port2"}}
+	spec.Routes = []openstackstablesapccv2.RouterRouteSpec{{Destination: "10.2.0.0/16", Nexthop: "10.0.0.254"}}
+	_, drift, err = n.SeedRouter("p1", spec)
+	assert.NoError(t, err)
+	assert.Empty(t, drift)
+	assert.Empty(t, actions, "router should be up to date")
+
+	router, _, err = n.SeedRouter("p1", openstackstablesapccv2.RouterSpec{
+		Name:                "new-router",
+		ExternalGatewayInfo: &openstackstablesapccv2.ExternalGatewayInfoSpec{NetworkId: "ext1"},
+	})
+	assert.NoError(t, err, "router should be created")
+	assert.Equal(t, "r2", router.ID)
+	assert.Equal(t, []string{"create router new-router"}, actions)
+
+	_, _, err = n.SeedRouter("p1", openstackstablesapccv2.RouterSpec{
+		Name:                "gw-router",
+		ExternalGatewayInfo: &openstackstablesapccv2.ExternalGatewayInfoSpec{Network: "unknown"},
+	})
+	assert.Error(t, err, "unknown external networks should be rejected")
+}
+
+func TestSeedAddressScope(t *testing.T) {
+	yes := true
+	spec := openstackstablesapccv2.AddressScopeSpec{
+		Name:      "scope-v4",
+		IpVersion: 4,
+		Shared:    &yes,
+		SubnetPools: []openstackstablesapccv2.SubnetPoolSpec{
+			{
+				Name:         "pool-v4",
+				Prefixes:     []string{"10.0.0.0/17", "10.0.128.0/17", "10.1.0.0/16"},
+				MinPrefixLen: 24,
+			},
+		},
+	}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleSubnetPoolsSuccessfully(t, &actions)
+
+	n := openstack.NewNeutron(client.ServiceClient(), nil)
+	assert.NoError(t, openstack.ValidateAddressScope(spec))
+	id, drift, err := n.SeedAddressScope("p1", spec)
+	assert.NoError(t, err)
+	assert.Equal(t, "as1", id)
+	assert.Empty(t, drift)
+	assert.Equal(t, []string{
+		"update address_scope scope-v4 shared=true",
+		`update subnetpool pool-v4 min_prefixlen=24 prefixes=["10.0.0.0/17","10.0.128.0/17","10.1.0.0/16"]`,
+	}, actions)
+
+	actions = nil
+	pool := openstackstablesapccv2.SubnetPoolSpec{Name: "pool-v4", Prefixes: []string{"10.0.0.0/17", "10.0.128.0/17"}, AddressScopeId: "as1", Shared: &yes}
+	drift, err = n.SeedSubnetPool("p1", pool)
+	assert.NoError(t, err)
+	if assert.Len(t, drift, 1) {
+		assert.Equal(t, "subnet pool pool-v4: shared is false instead of true", drift[0].String())
+	}
+	assert.Empty(t, actions, "merged prefixes should match the declared ones")
+
+	pool.Prefixes = []string{"10.0.0.0/17"}
+	_, err = n.SeedSubnetPool("p1", pool)
+	assert.Error(t, err, "prefixes should not shrink")
+	assert.Empty(t, actions)
+
+	id, _, err = n.SeedAddressScope("p1", openstackstablesapccv2.AddressScopeSpec{
+		Name:      "scope-v6",
+		IpVersion: 6,
+		SubnetPools: []openstackstablesapccv2.SubnetPoolSpec{
+			{Name: "pool-v6", Prefixes: []string{"2001:db8::/48"}, MinPrefixLen: 64, DefaultPrefixLen: 64},
+		},
+	})
+	assert.NoError(t, err, "address scope should be created")
+	assert.Equal(t, "as2", id)
+	assert.Equal(t, []string{"create address scope scope-v6", "create subnet pool pool-v6"}, actions)
+}
+
+func TestValidateAddressScope(t *testing.T) {
+	for msg, spec := range map[string]openstackstablesapccv2.AddressScopeSpec{
+		"invalid ip version": {Name: "scope", IpVersion: 5},
+		"pool without prefixes": {Name: "scope", IpVersion: 4, SubnetPools: []openstackstablesapccv2.SubnetPoolSpec{
+			{Name: "pool"},
+		}},
+		"prefix of other ip version": {Name: "scope", IpVersion: 4, SubnetPools: []openstackstablesapccv2.SubnetPoolSpec{
+			{Name: "pool", Prefixes: []string{"2001:db8::/48"}},
+		}},
+		"mixed ip versions": {Name: "scope", IpVersion: 4, SubnetPools: []openstackstablesapccv2.SubnetPoolSpec{
+			{Name: "pool", Prefixes: []string{"10.0.0.0/16", "2001:db8::/48"}},
+		}},
+		"prefix length too long": {Name: "scope", IpVersion: 4, SubnetPools: []openstackstablesapccv2.SubnetPoolSpec{
+			{Name: "pool", Prefixes: []string{"10.0.0.0/16"}, MaxPrefixLen: 64},
+		}},
+		"default outside min and max": {Name: "scope", IpVersion: 4, SubnetPools: []openstackstablesapccv2.SubnetPoolSpec{
+			{Name: "pool", Prefixes: []string{"10.0.0.0/16"}, MinPrefixLen: 24, DefaultPrefixLen: 20},
+		}},
+		"pool of other address scope": {Name: "scope", IpVersion: 4, SubnetPools: []openstackstablesapccv2.SubnetPoolSpec{
+			{Name: "pool", Prefixes: []string{"10.0.0.0/16"}, AddressScopeId: "as1"},
+		}},
+	} {
+		assert.Error(t, openstack.ValidateAddressScope(spec), msg)
+	}
+}
+
+func TestSeedNetworkQuota(t *testing.T) {
+	spec := openstackstablesapccv2.NetworkQuotaSpec{Network: 20, Port: 50, LoadBalancer: 5, HealthMonitor: -1}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleNetworkQuotaSuccessfully(t, &actions)
+
+	n := openstack.NewNeutron(client.ServiceClient(), nil)
+	changes, err := n.SeedNetworkQuota("p1", "admin@monsoon3", spec)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"update quota /quotas/p1 healthmonitor=-1 loadbalancer=5 network=20"}, actions)
+	if assert.Len(t, changes, 3) {
+		assert.Equal(t, "network", changes[0].Service)
+		assert.Equal(t, "healthmonitor", changes[0].Resource)
+		assert.Equal(t, int64(0), changes[0].Old)
+	}
+
+	actions = nil
+	n.Octavia = client.ServiceClient()
+	n.Octavia.ResourceBase = th.Endpoint() + "v2.0/"
+	changes, err = n.SeedNetworkQuota("p1", "admin@monsoon3", spec)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"update quota /quotas/p1 network=20",
+		"update quota /v2.0/quotas/p1 load_balancer=5",
+	}, actions, "load balancer quotas should be seeded through octavia")
+	if assert.Len(t, changes, 2) {
+		assert.Equal(t, "load-balancer", changes[1].Service)
+		assert.Equal(t, "load_balancer", changes[1].Resource)
+		assert.Equal(t, int64(10), changes[1].Old)
+	}
+}
+
+func TestSeedRBACPolicies(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleRBACPoliciesSuccessfully(t, &actions)
+	HandleNetworkLookupSuccessfully(t)
+	HandleProjectLookupSuccessfully(t)
+
+	n := openstack.NewNeutron(client.ServiceClient(), openstack.NewKeystone(client.ServiceClient()))
+	id, err := n.GetRBACObjectID("network", "private")
+	assert.NoError(t, err)
+	assert.Equal(t, "n1", id)
+	id, err = n.GetRBACObjectID("network", "storage@storage@monsoon3")
+	assert.NoError(t, err)
+	assert.Equal(t, "n2", id)
+	_, err = n.GetRBACObjectID("network", "private@storage@monsoon3")
+	assert.Error(t, err, "networks of other projects should not be found")
+
+	id, err = n.GetRBACTargetID("storage@monsoon3")
+	assert.NoError(t, err)
+	assert.Equal(t, "p2", id)
+	id, err = n.GetRBACTargetID("*")
+	assert.NoError(t, err)
+	assert.Equal(t, "*", id)
+
+	revoked, err := n.SeedRBACPolicies("network", "n1", "access_as_shared", []string{"p2", "*"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"p3"}, revoked)
+	assert.Equal(t, []string{"create network n1 access_as_shared *", "delete rbac2"}, actions)
+
+	actions = nil
+	revoked, err = n.SeedRBACPolicies("network", "n1", "access_as_shared", []string{"p2"}, false)
+	assert.NoError(t, err)
+	assert.Empty(t, revoked, "policies should only be deleted when pruning")
+	assert.Empty(t, actions)
+
+	revoked, err = n.SeedRBACPolicies("network", "n4", "access_as_shared", []string{"p3"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"p2"}, revoked, "shared networks are granted to all projects by neutron")
+	assert.Equal(t, []string{"create network n4 access_as_shared p3", "delete rbac5"}, actions)
+
+	actions = nil
+	revoked, err = n.SeedRBACPolicies("qos_policy", "q1", "access_as_shared", []string{"p2"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"p3"}, revoked, "shared qos policies are granted to all projects by neutron")
+	assert.Equal(t, []string{"create qos_policy q1 access_as_shared p2", "delete rbac7"}, actions)
+}
+
+func TestValidateRBACPolicy(t *testing.T) {
+	valid := openstackstablesapccv2.RBACPolicySpec{
+		ObjectType:       "network",
+		ObjectName:       "ext@admin@monsoon3",
+		Action:           "access_as_external",
+		TargetTenantName: "*",
+	}
+	assert.NoError(t, openstack.ValidateRBACPolicy(valid))
+
+	for msg, modify := range map[string]func(*openstackstablesapccv2.RBACPolicySpec){
+		"invalid object type":     func(p *openstackstablesapccv2.RBACPolicySpec) { p.ObjectType = "router" },
+		"invalid action":          func(p *openstackstablesapccv2.RBACPolicySpec) { p.Action = "access_as_owner" },
+		"external qos policy":     func(p *openstackstablesapccv2.RBACPolicySpec) { p.ObjectType = "qos_policy" },
+		"no object name":          func(p *openstackstablesapccv2.RBACPolicySpec) { p.ObjectName = "" },
+		"target without a domain": func(p *openstackstablesapccv2.RBACPolicySpec) { p.TargetTenantName = "admin" },
+	} {
+		spec := valid
+		modify(&spec)
+		assert.Error(t, openstack.ValidateRBACPolicy(spec), msg)
+	}
+}
+
+func TestSeedSecurityGroups(t *testing.T) {
+	specs := []openstackstablesapccv2.SecurityGroupSpec{
+		{
+			Name: "bastion",
+			Rules: []openstackstablesapccv2.SecurityGroupRuleSpec{
+				{Direction: "egress"},
+				// neutron stores the tcp rule by name and completes the port range
+				{Direction: "ingress", Protocol: "6", PortRangeMin: 22, RemoteIPPrefix: "10.1.2.3/8"},
+				{Direction: "ingress", Protocol: "ICMP", RemoteGroup: "bastion"},
+				{Direction: "ingress", Protocol: "tcp", PortRangeMin: 443, PortRangeMax: 443, RemoteIPPrefix: "0.0.0.0/0"},
+			},
+		},
+		{
+			Name:        "web",
+			Description: "web servers",
+			Rules: []openstackstablesapccv2.SecurityGroupRuleSpec{
+				{Direction: "ingress", EtherType: "IPv4", Protocol: "tcp", PortRangeMin: 80, PortRangeMax: 80, RemoteGroup: "bastion"},
+			},
+		},
+	}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleSecurityGroupsSuccessfully(t, &actions)
+
+	n := openstack.NewNeutron(client.ServiceClient(), nil)
+	for _, spec := range specs {
+		assert.NoError(t, openstack.ValidateSecurityGroup(spec))
+	}
+	err := n.SeedSecurityGroups("p1", specs, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"create security group web",
+		"create rule sg1 ingress IPv4 tcp 443-443 0.0.0.0/0",
+		"create rule sg2 ingress IPv4 tcp 80-80 sg1",
+	}, actions)
+
+	actions = nil
+	err = n.SeedSecurityGroups("p1", specs, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"create security group web",
+		"create rule sg1 ingress IPv4 tcp 443-443 0.0.0.0/0",
+		"delete rule rule2",
+		"create rule sg2 ingress IPv4 tcp 80-80 sg1",
+		"delete rule rule5",
+		"delete rule rule6",
+	}, actions, "undeclared rules should be deleted")
+
+	err = n.SeedSecurityGroups("p1", []openstackstablesapccv2.SecurityGroupSpec{
+		{Name: "bastion", Rules: []openstackstablesapccv2.SecurityGroupRuleSpec{{Direction: "ingress", RemoteGroup: "unknown"}}},
+	}, false)
+	assert.Error(t, err, "unknown remote groups should be rejected")
+}
+
+func TestValidateSecurityGroup(t *testing.T) {
+	for msg, rule := range map[string]openstackstablesapccv2.SecurityGroupRuleSpec{
+		"invalid direction":          {Direction: "in"},
+		"invalid ethertype":          {Direction: "ingress", EtherType: "ipv4"},
+		"invalid remote prefix":      {Direction: "ingress", RemoteIPPrefix: "10.0.0.0"},
+		"prefix of other ip version": {Direction: "ingress", EtherType: "IPv6", RemoteIPPrefix: "10.0.0.0/8"},
+		"remote prefix and group":    {Direction: "ingress", RemoteIPPrefix: "10.0.0.0/8", RemoteGroup: "bastion"},
+		"ports without protocol":     {Direction: "ingress", PortRangeMin: 22, PortRangeMax: 22},
+		"invalid port":               {Direction: "ingress", Protocol: "tcp", PortRangeMin: 1, PortRangeMax: 65536},
+		"min port after max port":    {Direction: "ingress", Protocol: "tcp", PortRangeMin: 443, PortRangeMax: 80},
+	} {
+		spec := openstackstablesapccv2.SecurityGroupSpec{Name: "bastion", Rules: []openstackstablesapccv2.SecurityGroupRuleSpec{rule}}
+		assert.Error(t, openstack.ValidateSecurityGroup(spec), msg)
+	}
+}
+
+func TestSeedQosPolicy(t *testing.T) {
+	yes := true
+	spec := openstackstablesapccv2.QosPolicySpec{
+		Name:   "gold",
+		Shared: &yes,
+		Rules: []openstackstablesapccv2.QosRuleSpec{
+			{Type: "bandwidth_limit", MaxKbps: 2000, MaxBurstKbps: 100},
+			{Type: "bandwidth_limit", MaxKbps: 1000, Direction: "ingress"},
+			{Type: "dscp_marking", DSCPMark: 26},
+		},
+	}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleQosPoliciesSuccessfully(t, &actions)
+
+	n := openstack.NewNeutron(client.ServiceClient(), nil)
+	id, err := n.SeedQosPolicy("p1", spec)
+	assert.NoError(t, err)
+	assert.Equal(t, "qp1", id)
+	assert.Equal(t, []string{
+		"PUT qp1 shared=true",
+		"PUT qp1/bandwidth_limit_rules/rl1 max_burst_kbps=100 max_kbps=2000",
+		`POST qp1/bandwidth_limit_rules direction="ingress" max_kbps=1000`,
+		"DELETE qp1/minimum_bandwidth_rules/rl3",
+	}, actions)
+
+	actions = nil
+	id, err = n.SeedQosPolicy("p9", openstackstablesapccv2.QosPolicySpec{
+		Name:   "silver",
+		Shared: &yes,
+		Rules:  []openstackstablesapccv2.QosRuleSpec{{Type: "dscp_marking", DSCPMark: 10}},
+	})
+	assert.NoError(t, err, "qos policy should be created")
+	assert.Equal(t, "qp2", id)
+	assert.Equal(t, []string{"create qos policy silver in p9", "POST qp2/dscp_marking_rules dscp_mark=10"}, actions)
+
+	actions = nil
+	_, err = n.SeedQosPolicy("p9", openstackstablesapccv2.QosPolicySpec{Name: "gold"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"create qos policy gold in p9"}, actions, "policies of other projects should not be taken over")
+
+	id, err = n.GetQosPolicyID("gold", "p2")
+	assert.NoError(t, err, "shared qos policies of other projects should be found")
+	assert.Equal(t, "qp1", id)
+	id, err = n.GetQosPolicyID("3b2a0a6c-5f2e-4f4b-9f3e-1d2c3b4a5e6f", "p2")
+	assert.NoError(t, err)
+	assert.Equal(t, "3b2a0a6c-5f2e-4f4b-9f3e-1d2c3b4a5e6f", id, "ids should not be looked up")
+	_, err = n.GetQosPolicyID("bronze", "p1")
+	assert.Error(t, err)
+
+	for msg, rule := range map[string]openstackstablesapccv2.QosRuleSpec{
+		"invalid type":                {Type: "packet_rate"},
+		"bandwidth limit without max": {Type: "bandwidth_limit"},
+		"minimum without min":         {Type: "minimum_bandwidth", MaxKbps: 1000},
+		"invalid dscp mark":           {Type: "dscp_marking", DSCPMark: 7},
+		"invalid direction":           {Type: "bandwidth_limit", MaxKbps: 1000, Direction: "both"},
+	} {
+		assert.Error(t, openstack.ValidateQosPolicy(openstackstablesapccv2.QosPolicySpec{Name: "gold", Rules: []openstackstablesapccv2.QosRuleSpec{rule}}), msg)
+	}
+	assert.Error(t, openstack.ValidateQosPolicy(openstackstablesapccv2.QosPolicySpec{Name: "gold", Rules: []openstackstablesapccv2.QosRuleSpec{
+		{Type: "bandwidth_limit", MaxKbps: 1000}, {Type: "bandwidth_limit", MaxKbps: 2000, Direction: "egress"},
+	}}), "policies cannot have two rules of the same type and direction")
+}
+
+func TestSeedPort(t *testing.T) {
+	spec := openstackstablesapccv2.PortSpec{
+		Name:                "vip",
+		Network:             "private",
+		FixedIPs:            []openstackstablesapccv2.FixedIPSpec{{Subnet: "private-v4"}},
+		MACAddress:          "fa:16:3e:00:00:02",
+		AllowedAddressPairs: []openstackstablesapccv2.AllowedAddressPairSpec{{IPAddress: "10.0.0.100"}, {IPAddress: "10.0.1.0/24"}},
+	}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandlePortsSuccessfully(t, &actions)
+
+	n := openstack.NewNeutron(client.ServiceClient(), nil)
+	port, drift, err := n.SeedPort("p1", spec)
+	assert.NoError(t, err)
+	assert.Equal(t, "pt1", port.ID)
+	assert.Equal(t, []openstack.Drift{
+		{Resource: "port vip", Field: "mac_address", Desired: "fa:16:3e:00:00:02", Actual: "fa:16:3e:00:00:01"},
+	}, drift, "mac addresses cannot be changed")
+	assert.Equal(t, []string{
+		`update port vip allowed_address_pairs=[{"ip_address":"10.0.0.100"},{"ip_address":"10.0.1.0/24"}]`,
+	}, actions, "fixed ips without address should keep the current one")
+
+	actions = nil
+	port, _, err = n.SeedPort("p1", openstackstablesapccv2.PortSpec{
+		Name:       "appliance",
+		Network:    "private",
+		FixedIPs:   []openstackstablesapccv2.FixedIPSpec{{Subnet: "private-v4", IPAddress: "10.0.0.10"}},
+		MACAddress: "fa:16:3e:00:00:10",
+	})
+	assert.NoError(t, err, "port should be created")
+	assert.Equal(t, "pt2", port.ID)
+	assert.Equal(t, []string{"create port appliance"}, actions)
+
+	actions = nil
+	fip, _, err := n.SeedFloatingIP("p1", openstackstablesapccv2.FloatingIPSpec{FloatingIPAddress: "192.0.2.10", FloatingNetwork: "ext", Port: "vip"})
+	assert.NoError(t, err)
+	assert.Equal(t, "pt1", fip.PortID)
+	fip, _, err = n.SeedFloatingIP("p1", openstackstablesapccv2.FloatingIPSpec{FloatingIPAddress: "192.0.2.11", FloatingNetwork: "ext"})
+	assert.NoError(t, err, "floating ip should be allocated")
+	assert.Equal(t, "fip2", fip.ID)
+	_, _, err = n.SeedFloatingIP("p1", openstackstablesapccv2.FloatingIPSpec{FloatingIPAddress: "192.0.2.12", FloatingNetwork: "ext"})
+	assert.Error(t, err, "floating ips of other projects must not be taken over")
+	assert.Equal(t, []string{`update floatingip 192.0.2.10 port_id="pt1"`, "create floating ip 192.0.2.11"}, actions)
+}
+
+func TestValidatePort(t *testing.T) {
+	for msg, spec := range map[string]openstackstablesapccv2.PortSpec{
+		"no network":              {Name: "vip"},
+		"fixed ip without subnet": {Name: "vip", Network: "private", FixedIPs: []openstackstablesapccv2.FixedIPSpec{{IPAddress: "10.0.0.5"}}},
+		"invalid ip address":      {Name: "vip", Network: "private", FixedIPs: []openstackstablesapccv2.FixedIPSpec{{Subnet: "private-v4", IPAddress: "10.0.0"}}},
+		"invalid mac address":     {Name: "vip", Network: "private", MACAddress: "fa:16:3e"},
+		"invalid address pair":    {Name: "vip", Network: "private", AllowedAddressPairs: []openstackstablesapccv2.AllowedAddressPairSpec{{IPAddress: "10.0.0.0/33"}}},
+	} {
+		assert.Error(t, openstack.ValidatePort(spec), msg)
+	}
+	for msg, spec := range map[string]openstackstablesapccv2.FloatingIPSpec{
+		"invalid address":       {FloatingIPAddress: "192.0.2", FloatingNetwork: "ext"},
+		"no network":            {FloatingIPAddress: "192.0.2.10"},
+		"fixed ip without port": {FloatingIPAddress: "192.0.2.10", FloatingNetwork: "ext", FixedIPAddress: "10.0.0.5"},
+	} {
+		assert.Error(t, openstack.ValidateFloatingIP(spec), msg)
+	}
+}
+
+func TestSeedNetworkSegmentRange(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleNetworkSegmentRangesSuccessfully(t, &actions)
+	HandleProjectLookupSuccessfully(t)
+
+	n := openstack.NewNeutron(client.ServiceClient(), openstack.NewKeystone(client.ServiceClient()))
+	drift, err := n.SeedNetworkSegmentRange(openstackstablesapccv2.NetworkSegmentRangeSpec{
+		Name: "tenant-vlans", NetworkType: "vlan", PhysicalNetwork: "physnet1", Minimum: 100, Maximum: 299, Project: "admin@monsoon3",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []openstack.Drift{
+		{Resource: "network segment range tenant-vlans", Field: "project", Desired: "admin@monsoon3", Actual: ""},
+	}, drift, "shared ranges cannot be assigned to a project")
+	assert.Equal(t, []string{"update network_segment_range tenant-vlans maximum=299 minimum=100"}, actions)
+
+	actions = nil
+	_, err = n.SeedNetworkSegmentRange(openstackstablesapccv2.NetworkSegmentRangeSpec{
+		Name: "hana-vlans", NetworkType: "vlan", PhysicalNetwork: "physnet1", Minimum: 300, Maximum: 399, Project: "hana@monsoon3",
+	})
+	assert.NoError(t, err, "network segment range should be created")
+	assert.Equal(t, []string{"create network segment range hana-vlans"}, actions)
+}
+
+func TestValidateNetworkSegmentRange(t *testing.T) {
+	for msg, spec := range map[string]openstackstablesapccv2.NetworkSegmentRangeSpec{
+		"no name":                     {NetworkType: "vxlan", Minimum: 1, Maximum: 100},
+		"invalid network type":        {Name: "flat", NetworkType: "flat", Minimum: 1, Maximum: 100},
+		"vlan without physnet":        {Name: "vlans", NetworkType: "vlan", Minimum: 1, Maximum: 100},
+		"vxlan with physnet":          {Name: "vxlans", NetworkType: "vxlan", PhysicalNetwork: "physnet1", Minimum: 1, Maximum: 100},
+		"vlan out of range":           {Name: "vlans", NetworkType: "vlan", PhysicalNetwork: "physnet1", Minimum: 1, Maximum: 4095},
+		"minimum larger than maximum": {Name: "vxlans", NetworkType: "vxlan", Minimum: 200, Maximum: 100},
+		"project without domain":      {Name: "vxlans", NetworkType: "vxlan", Minimum: 1, Maximum: 100, Project: "hana"},
+	} {
+		assert.Error(t, openstack.ValidateNetworkSegmentRange(spec), msg)
+	}
+
+	ranges := []openstackstablesapccv2.NetworkSegmentRangeSpec{
+		{Name: "tenant-vlans", NetworkType: "vlan", PhysicalNetwork: "physnet1", Minimum: 100, Maximum: 199},
+		{Name: "hana-vlans", NetworkType: "vlan", PhysicalNetwork: "physnet1", Minimum: 300, Maximum: 399, Project: "hana@monsoon3"},
+	}
+	network := func(id, physnet string) openstackstablesapccv2.NetworkSpec {
+		return openstackstablesapccv2.NetworkSpec{Name: "private", ProviderNetworkType: "vlan", ProviderPhysicalNetwork: physnet, ProviderSegmentationId: id}
+	}
+	assert.NoError(t, openstack.ValidateSegmentationID(network("150", "physnet1"), "admin@monsoon3", ranges))
+	assert.NoError(t, openstack.ValidateSegmentationID(network("350", "physnet1"), "hana@monsoon3", ranges))
+	assert.Error(t, openstack.ValidateSegmentationID(network("350", "physnet1"), "admin@monsoon3", ranges), "ranges of other projects cannot be used")
+	assert.Error(t, openstack.ValidateSegmentationID(network("250", "physnet1"), "admin@monsoon3", ranges), "ids outside of the ranges cannot be used")
+	assert.NoError(t, openstack.ValidateSegmentationID(network("250", "physnet2"), "admin@monsoon3", ranges), "physnets without ranges are not checked")
+}
@@ -0,0 +1,202 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"testing"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
+	"github.com/sapcc/openstack-seeder/openstack"
+	"github.com/stretchr/testify/assert"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+func TestSeedFlavor(t *testing.T) {
+	specEqual := openstackstablesapccv2.FlavorSpec{
+		Name:  "m1.small",
+		Ram:   2048,
+		Vcpus: 1,
+		Disk:  10,
+		ExtraSpecs: map[string]string{
+			"hw:cpu_policy":    "dedicated",
+			"hw:mem_page_size": "large",
+		},
+	}
+	specDrift := specEqual
+	specDrift.Ram = 4096
+	specNotExist := openstackstablesapccv2.FlavorSpec{
+		Name:  "m1.new",
+		Ram:   1024,
+		Vcpus: 1,
+	}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	HandleFlavorsSuccessfully(t)
+
+	n := openstack.NewNova(client.ServiceClient())
+	f, drift, err := n.SeedFlavor(specEqual)
+	assert.NoError(t, err, "extra specs should be updated")
+	assert.Equal(t, "1", f.ID)
+	assert.Empty(t, drift)
+
+	f, drift, err = n.SeedFlavor(specDrift)
+	assert.NoError(t, err, "drift should not fail the seed")
+	assert.Equal(t, "1", f.ID)
+	if assert.Len(t, drift, 1) {
+		assert.Equal(t, "flavor m1.small: ram is 2048 instead of 4096", drift[0].String())
+	}
+
+	f, drift, err = n.SeedFlavor(specNotExist)
+	assert.NoError(t, err, "flavor should be created")
+	assert.Equal(t, "2", f.ID)
+	assert.Empty(t, drift)
+}
+
+func TestSeedFlavorRecreate(t *testing.T) {
+	spec := openstackstablesapccv2.FlavorSpec{
+		Id:       "4",
+		Name:     "hana.small",
+		Ram:      4096,
+		Vcpus:    1,
+		IsPublic: new(bool),
+	}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleRecreateFlavorSuccessfully(t, &actions)
+
+	n := openstack.NewNova(client.ServiceClient())
+	n.RecreateFlavors = true
+	f, drift, err := n.SeedFlavor(spec)
+	assert.NoError(t, err, "flavor should be recreated")
+	assert.Equal(t, "4", f.ID)
+	assert.Empty(t, drift)
+	assert.Equal(t, []string{
+		"delete 4",
+		"create 4 ram 4096",
+		"addTenantAccess p1",
+		"addTenantAccess p2",
+	}, actions, "the access should be granted again")
+
+	// createFlavor cannot disable a flavor, so recreating it would not resolve the drift
+	yes := true
+	spec.Ram = 2048
+	spec.Disabled = &yes
+	actions = nil
+	_, drift, err = n.SeedFlavor(spec)
+	assert.NoError(t, err)
+	assert.Equal(t, []openstack.Drift{{Resource: "flavor hana.small", Field: "disabled", Desired: true, Actual: false}}, drift)
+	assert.Empty(t, actions, "a disabled drift alone should not recreate the flavor")
+
+	spec.Ram = 4096
+	_, drift, err = n.SeedFlavor(spec)
+	assert.NoError(t, err, "flavor should be recreated")
+	assert.Equal(t, []openstack.Drift{{Resource: "flavor hana.small", Field: "disabled", Desired: true, Actual: false}}, drift, "the disabled drift should still be reported")
+	assert.Equal(t, "delete 4", actions[0])
+}
+
+func TestSeedFlavorAccess(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	HandleFlavorsSuccessfully(t)
+	var actions []string
+	HandleFlavorAccessSuccessfully(t, &actions)
+
+	n := openstack.NewNova(client.ServiceClient())
+	err := n.SeedFlavorAccess("1", []string{"p1"}, true)
+	assert.NoError(t, err, "public flavors should be skipped")
+
+	err = n.SeedFlavorAccess("3", []string{"p1", "p3"}, false)
+	assert.NoError(t, err, "access should be granted")
+	assert.Equal(t, []string{"addTenantAccess p3"}, actions)
+
+	actions = nil
+	err = n.SeedFlavorAccess("3", []string{"p1", "p3"}, true)
+	assert.NoError(t, err, "access should be granted and revoked")
+	assert.Equal(t, []string{"addTenantAccess p3", "removeTenantAccess p2"}, actions)
+}
+
+func TestSeedAggregate(t *testing.T) {
+	spec := openstackstablesapccv2.AggregateSpec{
+		Name:             "bb091",
+		AvailabilityZone: "qa-de-1a",
+		Metadata:         map[string]string{"hana": "true"},
+		Hosts:            []string{"node001-bb091", "node003-bb091"},
+	}
+	specUnmanaged := openstackstablesapccv2.AggregateSpec{
+		Name:  "bb091",
+		Hosts: []string{},
+	}
+	specNotExist := openstackstablesapccv2.AggregateSpec{
+		Name:             "bb092",
+		AvailabilityZone: "qa-de-1b",
+	}
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var actions []string
+	HandleAggregatesSuccessfully(t, &actions)
+
+	n := openstack.NewNova(client.ServiceClient())
+	_, changes, err := n.SeedAggregate(spec)
+	assert.NoError(t, err, "aggregate should be updated")
+	assert.Equal(t, []string{
+		`set_metadata {"metadata":{"filter_tenant_id":null,"hana":"true"}}`,
+		`add_host {"host":"node003-bb091"}`,
+		`remove_host {"host":"node002-bb091"}`,
+	}, actions)
+	assert.Equal(t, []string{
+		"host node003-bb091 added to aggregate bb091",
+		"host node002-bb091 removed from aggregate bb091",
+	}, changes)
+
+	actions = nil
+	_, changes, err = n.SeedAggregate(specUnmanaged)
+	assert.NoError(t, err, "availability zone should be removed")
+	assert.Equal(t, []string{
+		`set_metadata {"metadata":{"availability_zone":null,"filter_tenant_id":null}}`,
+	}, actions, "hosts should not be managed")
+	assert.Empty(t, changes)
+
+	a, changes, err := n.SeedAggregate(specNotExist)
+	assert.NoError(t, err, "aggregate should be created")
+	assert.Equal(t, 2, a.ID)
+	assert.Empty(t, changes)
+}
+
+func TestSeedComputeQuota(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	HandleComputeQuotaSuccessfully(t)
+
+	n := openstack.NewNova(client.ServiceClient())
+	changes, err := n.SeedComputeQuota("p1", "project@domain", openstackstablesapccv2.ComputeQuotaSpec{
+		Instances: 10,
+		Cores:     40,
+		RAM:       102400,
+	})
+	assert.NoError(t, err, "changed keys should be updated")
+	assert.Equal(t, []openstackstablesapccv2.QuotaChange{
+		{Service: "compute", Project: "project@domain", Resource: "cores", Old: 20, New: 40},
+		{Service: "compute", Project: "project@domain", Resource: "ram", Old: 51200, New: 102400},
+	}, changes)
+
+	changes, err = n.SeedComputeQuota("p1", "project@domain", openstackstablesapccv2.ComputeQuotaSpec{Instances: 10})
+	assert.NoError(t, err, "update should not be called")
+	assert.Empty(t, changes)
+}
@@ -0,0 +1,58 @@
+/**
+ * Copyright 2021 SAP SE
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"testing"
+
+	"github.com/sapcc/openstack-seeder/openstack"
+	"github.com/stretchr/testify/assert"
+
+	th "github.com/gophercloud/gophercloud/testhelper"
+	"github.com/gophercloud/gophercloud/testhelper/client"
+)
+
+func TestSeedResourceClasses(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	var deleted []string
+	HandlePlacementSuccessfully(t, &deleted)
+
+	c := client.ServiceClient()
+	c.Type = "placement"
+	p := openstack.NewPlacement(c)
+	err := p.SeedResourceClasses([]string{"CUSTOM_BAREMETAL_LARGE", "CUSTOM_BAREMETAL_SMALL"})
+	assert.NoError(t, err, "resource classes should be created")
+
+	err = p.SeedResourceClasses([]string{"custom_baremetal"})
+	assert.Error(t, err, "invalid resource class names should be rejected")
+
+	pruned, err := p.PruneResourceClasses([]string{"CUSTOM_BAREMETAL_LARGE"})
+	assert.NoError(t, err, "undeclared resource classes should be deleted")
+	assert.Equal(t, []string{"CUSTOM_BAREMETAL_OLD"}, pruned)
+	assert.Equal(t, []string{"CUSTOM_BAREMETAL_OLD"}, deleted)
+}
+
+func TestSeedTraits(t *testing.T) {
+	th.SetupHTTP()
+	defer th.TeardownHTTP()
+	HandlePlacementSuccessfully(t, nil)
+
+	p := openstack.NewPlacement(client.ServiceClient())
+	assert.NoError(t, p.SeedTraits([]string{"CUSTOM_HANA"}), "trait should be created")
+	assert.Error(t, p.SeedTraits([]string{"HW_CPU_X86_AVX"}), "standard traits cannot be created")
+}
@@ -18,9 +18,91 @@ package openstack
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+
+	openstackstablesapccv2 "github.com/sapcc/openstack-seeder/api/v2"
 )
 
+// newProviderClient authenticates against keystone with the credentials from the OS_* environment variables.
+func newProviderClient() (provider *gophercloud.ProviderClient, err error) {
+	opts, err := openstack.AuthOptionsFromEnv()
+	if err != nil {
+		return
+	}
+	return openstack.AuthenticatedClient(opts)
+}
+
+// projectProviders caches the provider clients scoped to a project by project id.
+var projectProviders = struct {
+	sync.Mutex
+	clients map[string]*gophercloud.ProviderClient
+}{clients: make(map[string]*gophercloud.ProviderClient)}
+
+// newProjectProviderClient authenticates like newProviderClient, but scopes the token to the given project.
+// Some services (e.g. manila) only create resources in the project of the token. The provider clients are
+// cached per project and reauthenticate once their token expires.
+func newProjectProviderClient(projectID string) (provider *gophercloud.ProviderClient, err error) {
+	projectProviders.Lock()
+	defer projectProviders.Unlock()
+	if provider, ok := projectProviders.clients[projectID]; ok {
+		return provider, nil
+	}
+	opts, err := openstack.AuthOptionsFromEnv()
+	if err != nil {
+		return
+	}
+	opts.TenantID, opts.TenantName = projectID, ""
+	opts.AllowReauth = true
+	if provider, err = openstack.AuthenticatedClient(opts); err != nil {
+		return
+	}
+	projectProviders.clients[projectID] = provider
+	return
+}
+
+// regionEndpointOpts selects the service endpoints of the region in OS_REGION_NAME.
+func regionEndpointOpts() gophercloud.EndpointOpts {
+	return gophercloud.EndpointOpts{
+		Region: os.Getenv("OS_REGION_NAME"),
+	}
+}
+
+// Drift is an attribute of an existing resource which differs from its spec,
+// but cannot be changed in place.
+type Drift struct {
+	Resource string
+	Field    string
+	Desired  interface{}
+	Actual   interface{}
+}
+
+func (d Drift) String() string {
+	return fmt.Sprintf("%s: %s is %v instead of %v", d.Resource, d.Field, d.Actual, d.Desired)
+}
+
+// NotFoundError is returned by lookups if the referenced resource does not exist.
+type NotFoundError struct {
+	Kind string
+	Name string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("could not find %s: %s", e.Kind, e.Name)
+}
+
+// IsNotFound reports whether err is or wraps a NotFoundError.
+func IsNotFound(err error) bool {
+	var nf *NotFoundError
+	return errors.As(err, &nf)
+}
+
 func isEqual(spec, os interface{}) (equal bool) {
 	var specInterface map[string]interface{}
 	var osInterface map[string]interface{}
@@ -51,3 +133,46 @@ func mapSpecToOptions(spec, options interface{}) interface{} {
 	json.Unmarshal(d, &options)
 	return options
 }
+
+// quotaSetOpts is a partial quota set update. It implements the update opts builders
+// of the different quota APIs, which all accept the same request body.
+type quotaSetOpts map[string]interface{}
+
+func (opts quotaSetOpts) ToComputeQuotaUpdateMap() (map[string]interface{}, error) {
+	return map[string]interface{}{"quota_set": map[string]interface{}(opts)}, nil
+}
+
+func (opts quotaSetOpts) ToBlockStorageQuotaUpdateMap() (map[string]interface{}, error) {
+	return map[string]interface{}{"quota_set": map[string]interface{}(opts)}, nil
+}
+
+// diffQuota compares the quota spec with the current quota set. It returns the changed
+// keys as update opts and the changes for the seed status. Unset (zero) keys are ignored.
+func diffQuota(service, project string, spec interface{}, current map[string]interface{}) (opts quotaSetOpts, changes []openstackstablesapccv2.QuotaChange) {
+	var desired map[string]interface{}
+	b, _ := json.Marshal(spec)
+	json.Unmarshal(b, &desired)
+
+	keys := make([]string, 0, len(desired))
+	for k := range desired {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	opts = make(quotaSetOpts)
+	for _, k := range keys {
+		v, _ := desired[k].(float64)
+		old, _ := current[k].(float64)
+		if _, ok := current[k]; ok && old == v {
+			continue
+		}
+		opts[k] = int64(v)
+		changes = append(changes, openstackstablesapccv2.QuotaChange{
+			Service:  service,
+			Project:  project,
+			Resource: k,
+			Old:      int64(old),
+			New:      int64(v),
+		})
+	}
+	return
+}